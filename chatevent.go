@@ -0,0 +1,187 @@
+package dstparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// chat log types in server_chat_log.txt
+const (
+	ChatTypeSay          = "Say"
+	ChatTypeWhisper      = "Whisper"
+	ChatTypeJoin         = "Join Announcement"
+	ChatTypeLeave        = "Leave Announcement"
+	ChatTypeDeath        = "Death Announcement"
+	ChatTypeResurrect    = "Resurrect Announcement"
+	ChatTypeRoll         = "Roll Announcement"
+	ChatTypeVote         = "Vote Announcement"
+	ChatTypeAnnouncement = "Announcement"
+	ChatTypeKick         = "Kick Announcement"
+	ChatTypeBan          = "Ban Announcement"
+)
+
+// ChatEvent is a typed chat record in server_chat_log.txt
+type ChatEvent interface {
+	// Record returns the chat log record of this event, the raw line is available in ChatLog.Raw
+	Record() ChatLog
+}
+
+// Record returns the chat log itself
+func (c ChatLog) Record() ChatLog {
+	return c
+}
+
+// SayEvent is a public chat message
+type SayEvent struct {
+	ChatLog `mapstructure:",squash"`
+}
+
+// WhisperEvent is a chat message sent to nearby players
+type WhisperEvent struct {
+	ChatLog `mapstructure:",squash"`
+}
+
+// JoinEvent is announced when player joins the shard
+type JoinEvent struct {
+	ChatLog `mapstructure:",squash"`
+}
+
+// LeaveEvent is announced when player leaves the shard
+type LeaveEvent struct {
+	ChatLog `mapstructure:",squash"`
+}
+
+// DeathEvent is announced when player dies
+type DeathEvent struct {
+	ChatLog `mapstructure:",squash"`
+	// what killed the player
+	Cause string `mapstructure:"cause"`
+}
+
+// ResurrectEvent is announced when player is resurrected
+type ResurrectEvent struct {
+	ChatLog `mapstructure:",squash"`
+	// what resurrected the player
+	Source string `mapstructure:"source"`
+}
+
+// RollEvent is announced when player uses /roll
+type RollEvent struct {
+	ChatLog `mapstructure:",squash"`
+	Result  int `mapstructure:"result"`
+	Min     int `mapstructure:"min"`
+	Max     int `mapstructure:"max"`
+}
+
+// VoteEvent is announced when a vote finished
+type VoteEvent struct {
+	ChatLog `mapstructure:",squash"`
+	// egs. rollback passed
+	Outcome string `mapstructure:"outcome"`
+}
+
+// AnnouncementEvent is the message announced by server, egs. c_announce
+type AnnouncementEvent struct {
+	ChatLog `mapstructure:",squash"`
+}
+
+// KickEvent is announced when player is kicked, egs. by vote or by admin, the whole announcement is kept in Msg
+type KickEvent struct {
+	ChatLog `mapstructure:",squash"`
+}
+
+// BanEvent is announced when player is banned, the whole announcement is kept in Msg
+type BanEvent struct {
+	ChatLog `mapstructure:",squash"`
+}
+
+// UnknownEvent is the record whose type is not supported
+type UnknownEvent struct {
+	ChatLog `mapstructure:",squash"`
+}
+
+var (
+	// markers between player name and death cause, for each language
+	deathMarkers = []string{"死于：", "was killed by"}
+	// markers between player name and resurrect source, for each language
+	resurrectMarkers = []string{"复活自：", "was resurrected by"}
+
+	// (KU_xxx) name 83 (1-100)
+	rollPattern       = regexp.MustCompile(`^(?:\((KU_[^)]*)\) )?(.*) (\d+ \(\d+-\d+\))$`)
+	rollResultPattern = regexp.MustCompile(`^(\d+) \((\d+)-(\d+)\)$`)
+)
+
+// Event returns the typed event of chat log
+func (c ChatLog) Event() ChatEvent {
+	switch c.Type {
+	case ChatTypeSay:
+		return SayEvent{ChatLog: c}
+	case ChatTypeWhisper:
+		return WhisperEvent{ChatLog: c}
+	case ChatTypeJoin:
+		return JoinEvent{ChatLog: c}
+	case ChatTypeLeave:
+		return LeaveEvent{ChatLog: c}
+	case ChatTypeDeath:
+		return DeathEvent{ChatLog: c, Cause: markerValue(c.Msg, deathMarkers)}
+	case ChatTypeResurrect:
+		return ResurrectEvent{ChatLog: c, Source: markerValue(c.Msg, resurrectMarkers)}
+	case ChatTypeRoll:
+		event := RollEvent{ChatLog: c}
+		if match := rollResultPattern.FindStringSubmatch(c.Msg); match != nil {
+			event.Result, _ = strconv.Atoi(match[1])
+			event.Min, _ = strconv.Atoi(match[2])
+			event.Max, _ = strconv.Atoi(match[3])
+		}
+		return event
+	case ChatTypeVote:
+		return VoteEvent{ChatLog: c, Outcome: c.Msg}
+	case ChatTypeAnnouncement:
+		return AnnouncementEvent{ChatLog: c}
+	case ChatTypeKick:
+		return KickEvent{ChatLog: c}
+	case ChatTypeBan:
+		return BanEvent{ChatLog: c}
+	}
+	return UnknownEvent{ChatLog: c}
+}
+
+// ParseServerChatEvents parses server_chat_log.txt into typed events,
+// errors of unparseable lines are returned as ParseServerChatLogs does.
+func ParseServerChatEvents(content []byte) ([]ChatEvent, error) {
+	logs, err := ParseServerChatLogs(content)
+	events := make([]ChatEvent, 0, len(logs))
+	for _, log := range logs {
+		events = append(events, log.Event())
+	}
+	return events, err
+}
+
+// splitMarker splits announcement into player name and the rest starts with the first matched marker
+func splitMarker(body string, markers []string) (name, rest string, ok bool) {
+	for _, marker := range markers {
+		if idx := strings.Index(body, " "+marker); idx > 0 {
+			return body[:idx], body[idx+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// markerValue returns the text after marker, cut at the end of the first sentence
+func markerValue(msg string, markers []string) string {
+	for _, marker := range markers {
+		if !strings.HasPrefix(msg, marker) {
+			continue
+		}
+		value := msg[len(marker):]
+		if end := strings.IndexAny(value, "。！!"); end >= 0 {
+			value = value[:end]
+		}
+		if end := strings.Index(value, ". "); end >= 0 {
+			value = value[:end]
+		}
+		return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "."))
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseServerChatEvents(t *testing.T) {
+	const chatlog = `[00:01:18]: [Join Announcement] 寒江蓑笠翁
+[00:01:57]: [Say] (KU_iJIpcpXi) 寒江蓑笠翁: aka
+[00:02:10]: [Whisper] (KU_iJIpcpXi) 寒江蓑笠翁: psst
+[00:05:32]: [Death Announcement] 寒江蓑笠翁 死于： 恶作剧。他变成了可怕的鬼魂！
+[00:06:37]: [Resurrect Announcement] 寒江蓑笠翁 复活自： 绚丽之门.
+[01:35:20]: [Roll Announcement] (KU_iJIpcpXi) 寒江蓑笠翁 83 (1-100)
+[01:35:27]: [Vote Announcement] rollback passed
+[01:36:00]: [Announcement] server will restart
+[01:36:10]: [Leave Announcement] 寒江蓑笠翁`
+
+	events, err := ParseServerChatEvents([]byte(chatlog))
+	assert.Nil(t, err)
+	assert.Len(t, events, 9)
+
+	assert.IsType(t, JoinEvent{}, events[0])
+	assert.EqualValues(t, "寒江蓑笠翁", events[0].Record().Name)
+
+	say := events[1].(SayEvent)
+	assert.EqualValues(t, "KU_iJIpcpXi", say.KleiId)
+	assert.EqualValues(t, "aka", say.Msg)
+
+	assert.IsType(t, WhisperEvent{}, events[2])
+
+	death := events[3].(DeathEvent)
+	assert.EqualValues(t, "寒江蓑笠翁", death.Name)
+	assert.EqualValues(t, "恶作剧", death.Cause)
+
+	resurrect := events[4].(ResurrectEvent)
+	assert.EqualValues(t, "绚丽之门", resurrect.Source)
+
+	roll := events[5].(RollEvent)
+	assert.EqualValues(t, 83, roll.Result)
+	assert.EqualValues(t, 1, roll.Min)
+	assert.EqualValues(t, 100, roll.Max)
+	assert.EqualValues(t, "寒江蓑笠翁", roll.Name)
+
+	vote := events[6].(VoteEvent)
+	assert.EqualValues(t, "rollback passed", vote.Outcome)
+
+	announcement := events[7].(AnnouncementEvent)
+	assert.EqualValues(t, "server will restart", announcement.Msg)
+
+	assert.IsType(t, LeaveEvent{}, events[8])
+	assert.EqualValues(t, "[01:36:10]: [Leave Announcement] 寒江蓑笠翁", events[8].Record().Raw)
+}
+
+func TestChatEventEnglish(t *testing.T) {
+	events, err := ParseServerChatEvents([]byte(`[00:05:32]: [Death Announcement] Wilson was killed by Spider. He became a ghost!
+[00:06:37]: [Resurrect Announcement] Wilson was resurrected by Touch Stone.`))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Spider", events[0].(DeathEvent).Cause)
+	assert.EqualValues(t, "Wilson", events[0].(DeathEvent).Name)
+	assert.EqualValues(t, "Touch Stone", events[1].(ResurrectEvent).Source)
+}
+
+func TestChatEventKickBan(t *testing.T) {
+	events, err := ParseServerChatEvents([]byte(`[00:02:00]: [Kick Announcement] Bobby has been kicked from the game.
+[00:03:00]: [Ban Announcement] Bob Smith has been banned from the game.`))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "Bobby has been kicked from the game.", events[0].(KickEvent).Msg)
+	assert.EqualValues(t, "kick", ChatEventKind(events[0]))
+	assert.EqualValues(t, "Bob Smith has been banned from the game.", events[1].(BanEvent).Msg)
+	assert.EqualValues(t, "ban", ChatEventKind(events[1]))
+}
@@ -0,0 +1,179 @@
+package dstparser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	chatLogColumns   = []string{"time", "offset", "timestamp", "type", "klei_id", "player_name", "msg"}
+	chatEventColumns = append(append([]string{"event"}, chatLogColumns...), "cause", "source", "result", "min", "max", "outcome")
+)
+
+// ChatEventKind returns the kind name of event, egs. say, death
+func ChatEventKind(event ChatEvent) string {
+	switch event.(type) {
+	case SayEvent:
+		return "say"
+	case WhisperEvent:
+		return "whisper"
+	case JoinEvent:
+		return "join"
+	case LeaveEvent:
+		return "leave"
+	case DeathEvent:
+		return "death"
+	case ResurrectEvent:
+		return "resurrect"
+	case RollEvent:
+		return "roll"
+	case VoteEvent:
+		return "vote"
+	case AnnouncementEvent:
+		return "announcement"
+	case KickEvent:
+		return "kick"
+	case BanEvent:
+		return "ban"
+	}
+	return "unknown"
+}
+
+// chatLogValues returns the exported fields of chat log, offset is in seconds, timestamp is omitted if not stamped
+func chatLogValues(log ChatLog) map[string]any {
+	values := map[string]any{
+		"time":        log.Time,
+		"offset":      int64(log.Offset / time.Second),
+		"type":        log.Type,
+		"klei_id":     log.KleiId,
+		"player_name": log.Name,
+		"msg":         log.Msg,
+	}
+	if !log.Timestamp.IsZero() {
+		values["timestamp"] = log.Timestamp.Format(time.RFC3339)
+	}
+	return values
+}
+
+// chatEventValues returns the exported fields of event, including the typed fields
+func chatEventValues(event ChatEvent) map[string]any {
+	values := chatLogValues(event.Record())
+	values["event"] = ChatEventKind(event)
+	switch e := event.(type) {
+	case DeathEvent:
+		values["cause"] = e.Cause
+	case ResurrectEvent:
+		values["source"] = e.Source
+	case RollEvent:
+		values["result"], values["min"], values["max"] = e.Result, e.Min, e.Max
+	case VoteEvent:
+		values["outcome"] = e.Outcome
+	}
+	return values
+}
+
+func writeJSONLines(w io.Writer, n int, values func(i int) map[string]any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for i := 0; i < n; i++ {
+		if err := encoder.Encode(values(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, columns []string, n int, values func(i int) map[string]any) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for i := 0; i < n; i++ {
+		row := values(i)
+		for j, column := range columns {
+			record[j] = ""
+			if value, ok := row[column]; ok {
+				record[j] = fmt.Sprint(value)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteChatLogsJSONL writes chat logs as JSON Lines, one object per log
+func WriteChatLogsJSONL(w io.Writer, logs []ChatLog) error {
+	return writeJSONLines(w, len(logs), func(i int) map[string]any {
+		return chatLogValues(logs[i])
+	})
+}
+
+// WriteChatEventsJSONL writes typed chat events as JSON Lines, with the event kind and typed fields
+func WriteChatEventsJSONL(w io.Writer, events []ChatEvent) error {
+	return writeJSONLines(w, len(events), func(i int) map[string]any {
+		return chatEventValues(events[i])
+	})
+}
+
+// WriteChatLogsCSV writes chat logs as CSV with header
+func WriteChatLogsCSV(w io.Writer, logs []ChatLog) error {
+	return writeCSV(w, chatLogColumns, len(logs), func(i int) map[string]any {
+		return chatLogValues(logs[i])
+	})
+}
+
+// WriteChatEventsCSV writes typed chat events as CSV with header, typed fields not belonged to the event are left empty
+func WriteChatEventsCSV(w io.Writer, events []ChatEvent) error {
+	return writeCSV(w, chatEventColumns, len(events), func(i int) map[string]any {
+		return chatEventValues(events[i])
+	})
+}
+
+// FormatChatLog formats chat log as a line in server_chat_log.txt, without line ending
+func FormatChatLog(log ChatLog) string {
+	t := log.Time
+	if len(t) == 0 {
+		t = FormatLogOffset(log.Offset)
+	}
+
+	var body string
+	switch log.Type {
+	case ChatTypeSay, ChatTypeWhisper:
+		body = fmt.Sprintf("(%s) %s: %s", log.KleiId, log.Name, log.Msg)
+	case ChatTypeJoin, ChatTypeLeave:
+		body = log.Name
+	case ChatTypeDeath, ChatTypeResurrect:
+		// name is empty if the marker is unknown, the whole body is kept in Msg
+		body = log.Msg
+		if len(log.Name) > 0 {
+			body = log.Name + " " + log.Msg
+		}
+	case ChatTypeRoll:
+		body = log.Name + " " + log.Msg
+		if len(log.KleiId) > 0 {
+			body = fmt.Sprintf("(%s) %s", log.KleiId, body)
+		}
+	default:
+		body = log.Msg
+	}
+
+	return fmt.Sprintf("[%s]: [%s] %s", t, log.Type, body)
+}
+
+// ToServerChatLogs converts chat logs to server_chat_log.txt, parsing the output gives the same logs
+func ToServerChatLogs(logs []ChatLog) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	for _, log := range logs {
+		buffer.WriteString(FormatChatLog(log))
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,103 @@
+package dstparser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testExportChatLog = `[00:01:18]: [Join Announcement] 寒江 蓑笠翁
+[00:01:57]: [Say] (KU_iJIpcpXi) 寒江 蓑笠翁: aka: "quoted", <b>
+[00:02:10]: [Whisper] (KU_iJIpcpXi) 寒江 蓑笠翁: psst
+[00:05:32]: [Death Announcement] 寒江 蓑笠翁 死于： 恶作剧。他变成了可怕的鬼魂！
+[00:06:37]: [Resurrect Announcement] 寒江 蓑笠翁 复活自： 绚丽之门.
+[01:35:20]: [Roll Announcement] (KU_iJIpcpXi) 寒江 蓑笠翁 83 (1-100)
+[01:35:27]: [Vote Announcement] rollback passed
+[01:36:00]: [Announcement] server will restart
+[01:36:10]: [Leave Announcement] 寒江 蓑笠翁
+`
+
+func TestToServerChatLogs(t *testing.T) {
+	logs, err := ParseServerChatLogs([]byte(testExportChatLog))
+	assert.Nil(t, err)
+
+	content, err := ToServerChatLogs(logs)
+	assert.Nil(t, err)
+	assert.EqualValues(t, testExportChatLog, string(content))
+
+	reparsed, err := ParseServerChatLogs(content)
+	assert.Nil(t, err)
+	assert.EqualValues(t, logs, reparsed)
+
+	// without the original time string
+	log := logs[1]
+	log.Time = ""
+	assert.EqualValues(t, strings.Split(testExportChatLog, "\n")[1], FormatChatLog(log))
+
+	// name is empty if the marker is unknown
+	const unknownMarker = "[00:07:00]: [Death Announcement] Вилсон был убит: Паук."
+	logs, err = ParseServerChatLogs([]byte(unknownMarker))
+	assert.Nil(t, err)
+	assert.Empty(t, logs[0].Name)
+	assert.EqualValues(t, unknownMarker, FormatChatLog(logs[0]))
+	reparsed, err = ParseServerChatLogs([]byte(FormatChatLog(logs[0])))
+	assert.Nil(t, err)
+	assert.EqualValues(t, logs, reparsed)
+}
+
+func TestWriteChatLogsJSONL(t *testing.T) {
+	logs, err := ParseServerChatLogs([]byte(testExportChatLog))
+	assert.Nil(t, err)
+	StampChatLogs(logs, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	buffer := bytes.NewBuffer(nil)
+	assert.Nil(t, WriteChatLogsJSONL(buffer, logs))
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, len(logs))
+
+	var record map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.EqualValues(t, `aka: "quoted", <b>`, record["msg"])
+	assert.EqualValues(t, 117, record["offset"])
+	assert.EqualValues(t, "2024-01-01T00:01:57Z", record["timestamp"])
+
+	events, err := ParseServerChatEvents([]byte(testExportChatLog))
+	assert.Nil(t, err)
+	buffer.Reset()
+	assert.Nil(t, WriteChatEventsJSONL(buffer, events))
+	t.Log(buffer.String())
+	lines = strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Nil(t, json.Unmarshal([]byte(lines[5]), &record))
+	assert.EqualValues(t, "roll", record["event"])
+	assert.EqualValues(t, 83, record["result"])
+}
+
+func TestWriteChatLogsCSV(t *testing.T) {
+	events, err := ParseServerChatEvents([]byte(testExportChatLog))
+	assert.Nil(t, err)
+
+	buffer := bytes.NewBuffer(nil)
+	assert.Nil(t, WriteChatEventsCSV(buffer, events))
+	t.Log(buffer.String())
+
+	records, err := csv.NewReader(buffer).ReadAll()
+	assert.Nil(t, err)
+	assert.Len(t, records, len(events)+1)
+	assert.EqualValues(t, chatEventColumns, records[0])
+	assert.EqualValues(t, "death", records[4][0])
+	assert.EqualValues(t, "恶作剧", records[4][8])
+
+	logs := make([]ChatLog, 0, len(events))
+	for _, event := range events {
+		logs = append(logs, event.Record())
+	}
+	buffer.Reset()
+	assert.Nil(t, WriteChatLogsCSV(buffer, logs))
+	records, err = csv.NewReader(buffer).ReadAll()
+	assert.Nil(t, err)
+	assert.EqualValues(t, `aka: "quoted", <b>`, records[2][6])
+}
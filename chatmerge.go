@@ -0,0 +1,87 @@
+package dstparser
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultMergeTolerance is the usual max time difference of the same announcement written in different shards
+const DefaultMergeTolerance = 2 * time.Second
+
+// ShardChatLog is the chat logs of a shard
+type ShardChatLog struct {
+	Shard string
+	// session start time of the shard, see StartFromModTime and ParseServerLogStartTime
+	Start time.Time
+	Logs  []ChatLog
+}
+
+// ShardChatEvent is a chat event in merged timeline
+type ShardChatEvent struct {
+	ChatEvent
+	// the shard which the event comes from, the first one if it appears in multiple shards
+	Shard string
+	// all shards which the event appears in
+	Shards []string
+	// absolute time of the event
+	Time time.Time
+}
+
+// MergeChatLogs merges chat logs of shards into one timeline ordered by time, each event is tagged with its shard.
+// Cluster-wide messages and announcements written in several shards are deduplicated, whispers are always kept
+// since they are local to the shard. Events are taken as the same if their time differs no more than tolerance,
+// egs. DefaultMergeTolerance.
+func MergeChatLogs(shards []ShardChatLog, tolerance time.Duration) []ShardChatEvent {
+	var events []ShardChatEvent
+	for _, shard := range shards {
+		logs := append([]ChatLog(nil), shard.Logs...)
+		StampChatLogs(logs, shard.Start)
+		for _, log := range logs {
+			events = append(events, ShardChatEvent{
+				ChatEvent: log.Event(),
+				Shard:     shard.Shard,
+				Shards:    []string{shard.Shard},
+				Time:      log.Timestamp,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	merged := make([]ShardChatEvent, 0, len(events))
+	for _, event := range events {
+		if dup := findDuplicate(merged, event, tolerance); dup >= 0 {
+			merged[dup].Shards = append(merged[dup].Shards, event.Shard)
+			continue
+		}
+		merged = append(merged, event)
+	}
+	return merged
+}
+
+// findDuplicate returns the index of the same event from another shard in merged, -1 if not found
+func findDuplicate(merged []ShardChatEvent, event ShardChatEvent, tolerance time.Duration) int {
+	log := event.Record()
+	if log.Type == ChatTypeWhisper {
+		return -1
+	}
+	for i := len(merged) - 1; i >= 0; i-- {
+		if event.Time.Sub(merged[i].Time) > tolerance {
+			break
+		}
+		other := merged[i].Record()
+		if other.Type != log.Type || other.KleiId != log.KleiId || other.Name != log.Name || other.Msg != log.Msg {
+			continue
+		}
+		seen := false
+		for _, shard := range merged[i].Shards {
+			seen = seen || shard == event.Shard
+		}
+		if !seen {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,44 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMergeChatLogs(t *testing.T) {
+	master, err := ParseServerChatLogs([]byte(`[00:01:00]: [Join Announcement] Wilson
+[00:01:10]: [Say] (KU_wilson1) Wilson: going down
+[00:03:00]: [Vote Announcement] rollback passed`))
+	assert.Nil(t, err)
+	caves, err := ParseServerChatLogs([]byte(`[00:00:01]: [Join Announcement] Wilson
+[00:01:00]: [Whisper] (KU_willow1) Willow: psst
+[00:01:59]: [Vote Announcement] rollback passed`))
+	assert.Nil(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	shards := []ShardChatLog{
+		{Shard: "Master", Start: start, Logs: master},
+		// caves started one minute later
+		{Shard: "Caves", Start: start.Add(time.Minute), Logs: caves},
+	}
+	events := MergeChatLogs(shards, DefaultMergeTolerance)
+
+	for _, event := range events {
+		t.Log(event.Time, event.Shards, event.Record().Raw)
+	}
+	assert.Len(t, events, 4)
+
+	assert.IsType(t, JoinEvent{}, events[0].ChatEvent)
+	assert.EqualValues(t, []string{"Master", "Caves"}, events[0].Shards)
+	assert.EqualValues(t, "Master", events[1].Shard)
+	assert.IsType(t, SayEvent{}, events[1].ChatEvent)
+	assert.EqualValues(t, "Caves", events[2].Shard)
+	assert.IsType(t, WhisperEvent{}, events[2].ChatEvent)
+	assert.IsType(t, VoteEvent{}, events[3].ChatEvent)
+	assert.EqualValues(t, []string{"Caves", "Master"}, events[3].Shards)
+	assert.EqualValues(t, start.Add(2*time.Minute+59*time.Second), events[3].Time)
+
+	// announcements are one second apart
+	assert.Len(t, MergeChatLogs(shards, 0), 6)
+}
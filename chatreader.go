@@ -0,0 +1,240 @@
+package dstparser
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ChatLogReader reads chat logs from io.Reader line by line
+type ChatLogReader struct {
+	reader *bufio.Reader
+	parser chatLogParser
+	line   int
+	offset int64
+}
+
+// NewChatLogReader returns a ChatLogReader reading from r
+func NewChatLogReader(r io.Reader) *ChatLogReader {
+	return &ChatLogReader{reader: bufio.NewReader(r)}
+}
+
+// Next returns the next chat log, returns io.EOF if there is no more lines.
+// Unparseable line is reported as *ChatLogSyntaxError, and the reading can be continued.
+func (r *ChatLogReader) Next() (ChatLog, error) {
+	for {
+		line, err := r.reader.ReadString('\n')
+		if len(line) == 0 && err != nil {
+			return ChatLog{}, err
+		}
+		r.line++
+		r.offset += int64(len(line))
+
+		line = strings.TrimRight(line, "\r\n")
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		log, perr := r.parser.parseLine(line)
+		if perr != nil {
+			return ChatLog{}, &ChatLogSyntaxError{Line: r.line, Raw: line, Reason: perr.Error()}
+		}
+		return log, nil
+	}
+}
+
+// Offset returns the number of bytes consumed
+func (r *ChatLogReader) Offset() int64 {
+	return r.offset
+}
+
+// ChatLogCheckpoint is the position of tailing, it can be persisted and used to resume tailing.
+type ChatLogCheckpoint struct {
+	// byte offset of the next line to read
+	Offset int64 `json:"offset"`
+	// first line of the file, used to detect whether file was recreated, not checked if empty
+	Head string `json:"head"`
+}
+
+// TailOptions is the options of TailChatLog
+type TailOptions struct {
+	// position to resume from, zero means read from the beginning
+	Checkpoint ChatLogCheckpoint
+	// interval of checking new lines, default is 1s
+	PollInterval time.Duration
+	// OnError is called for unparseable lines, they are skipped if nil
+	OnError func(err error)
+}
+
+// TailChatLog follows server_chat_log.txt at path and calls fn for each line appended, with the checkpoint after that line.
+// File truncation and recreation on server restart are detected, the tailing restarts from the beginning of the new file.
+// It blocks until ctx is done or fn returns error.
+func TailChatLog(ctx context.Context, path string, opts TailOptions, fn func(log ChatLog, checkpoint ChatLogCheckpoint) error) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	tail := &chatLogTail{path: path, opts: opts, checkpoint: opts.Checkpoint}
+	defer tail.close()
+
+	for {
+		if err := tail.follow(fn); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+type chatLogTail struct {
+	path       string
+	opts       TailOptions
+	file       *os.File
+	info       os.FileInfo
+	reader     *bufio.Reader
+	parser     chatLogParser
+	line       int
+	pending    string
+	checkpoint ChatLogCheckpoint
+}
+
+func (t *chatLogTail) close() {
+	if t.file != nil {
+		_ = t.file.Close()
+		t.file = nil
+	}
+}
+
+// follow reads all complete lines available, if the file was recreated, the remaining lines of old file
+// are read before switching to the new file.
+func (t *chatLogTail) follow(fn func(log ChatLog, checkpoint ChatLogCheckpoint) error) error {
+	for {
+		recreated, err := t.check()
+		if errors.Is(err, os.ErrNotExist) {
+			if t.file != nil {
+				// renamed, the new file is not created yet
+				return t.read(fn)
+			}
+			// wait for server creating the file
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := t.read(fn); err != nil {
+			return err
+		}
+		if !recreated {
+			return nil
+		}
+		t.close()
+		t.checkpoint = ChatLogCheckpoint{}
+	}
+}
+
+// read reads all complete lines from the opened file
+func (t *chatLogTail) read(fn func(log ChatLog, checkpoint ChatLogCheckpoint) error) error {
+	for {
+		chunk, err := t.reader.ReadString('\n')
+		t.pending += chunk
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		raw := t.pending
+		t.pending = ""
+		t.line++
+		if t.checkpoint.Offset == 0 {
+			t.checkpoint.Head = strings.TrimRight(raw, "\r\n")
+		}
+		t.checkpoint.Offset += int64(len(raw))
+
+		line := strings.TrimRight(raw, "\r\n")
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		log, perr := t.parser.parseLine(line)
+		if perr != nil {
+			if t.opts.OnError != nil {
+				t.opts.OnError(&ChatLogSyntaxError{Line: t.line, Raw: line, Reason: perr.Error()})
+			}
+			continue
+		}
+		if err := fn(log, t.checkpoint); err != nil {
+			return err
+		}
+	}
+}
+
+// sameHead reports whether the first line of opened file is still the checkpoint head
+func (t *chatLogTail) sameHead() bool {
+	if len(t.checkpoint.Head) == 0 {
+		return true
+	}
+	head := make([]byte, len(t.checkpoint.Head))
+	if _, err := t.file.ReadAt(head, 0); err != nil {
+		return false
+	}
+	return string(head) == t.checkpoint.Head
+}
+
+// check opens the file if needed, and reopens it if it was truncated. It reports whether the file was recreated,
+// the old file is kept open so that its remaining lines can be read before switching.
+func (t *chatLogTail) check() (bool, error) {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return false, err
+	}
+
+	if t.file != nil {
+		if !os.SameFile(t.info, info) {
+			return true, nil
+		} else if info.Size() < t.checkpoint.Offset+int64(len(t.pending)) || !t.sameHead() {
+			// truncated and rewritten
+			t.close()
+			t.checkpoint = ChatLogCheckpoint{}
+		} else {
+			t.info = info
+			return false, nil
+		}
+	}
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return false, err
+	}
+	if info, err = file.Stat(); err != nil {
+		_ = file.Close()
+		return false, err
+	}
+	t.file, t.info, t.pending, t.line = file, info, "", 0
+	t.parser = chatLogParser{}
+	t.reader = bufio.NewReader(file)
+
+	// validate the checkpoint
+	if t.checkpoint.Offset > 0 {
+		head, err := t.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		headChanged := len(t.checkpoint.Head) > 0 && strings.TrimRight(head, "\r\n") != t.checkpoint.Head
+		if info.Size() < t.checkpoint.Offset || headChanged {
+			t.checkpoint = ChatLogCheckpoint{}
+		}
+		if _, err := file.Seek(t.checkpoint.Offset, io.SeekStart); err != nil {
+			return false, err
+		}
+		t.reader.Reset(file)
+	}
+
+	return false, nil
+}
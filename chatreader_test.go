@@ -0,0 +1,136 @@
+package dstparser
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChatLogReader(t *testing.T) {
+	const chatlog = "[00:01:18]: [Join Announcement] 寒江蓑笠翁\r\n" +
+		"bad line\n" +
+		"[00:01:57]: [Say] (KU_iJIpcpXi) 寒江蓑笠翁: aka"
+
+	reader := NewChatLogReader(strings.NewReader(chatlog))
+	log, err := reader.Next()
+	assert.Nil(t, err)
+	assert.EqualValues(t, ChatTypeJoin, log.Type)
+
+	_, err = reader.Next()
+	var syntaxErr *ChatLogSyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+
+	log, err = reader.Next()
+	assert.Nil(t, err)
+	assert.EqualValues(t, "aka", log.Msg)
+	assert.EqualValues(t, len(chatlog), reader.Offset())
+
+	_, err = reader.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestTailChatLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server_chat_log.txt")
+	assert.Nil(t, os.WriteFile(path, []byte("[00:00:01]: [Say] (KU_iJIpcpXi) a: 1\n"), 0644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logs := make(chan ChatLog, 16)
+	var checkpoint ChatLogCheckpoint
+	done := make(chan error)
+	go func() {
+		done <- TailChatLog(ctx, path, TailOptions{PollInterval: 10 * time.Millisecond}, func(log ChatLog, cp ChatLogCheckpoint) error {
+			checkpoint = cp
+			logs <- log
+			return nil
+		})
+	}()
+
+	next := func() string {
+		select {
+		case log := <-logs:
+			return log.Msg
+		case <-ctx.Done():
+			return ""
+		}
+	}
+	assert.EqualValues(t, "1", next())
+
+	// append, with a partial line written first
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.Nil(t, err)
+	_, _ = file.WriteString("[00:00:02]: [Say] (KU_iJIpcpXi) a: ")
+	time.Sleep(50 * time.Millisecond)
+	_, _ = file.WriteString("2\n")
+	assert.Nil(t, file.Close())
+	assert.EqualValues(t, "2", next())
+
+	// recreated on restart
+	assert.Nil(t, os.Remove(path))
+	assert.Nil(t, os.WriteFile(path, []byte("[00:00:01]: [Say] (KU_iJIpcpXi) a: 3\n"), 0644))
+	assert.EqualValues(t, "3", next())
+
+	// truncated
+	assert.Nil(t, os.WriteFile(path, []byte("[00:00:01]: [Say] (KU_iJIpcpXi) a: 4\n"), 0644))
+	assert.EqualValues(t, "4", next())
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	// resume from checkpoint
+	assert.Nil(t, os.WriteFile(path, []byte("[00:00:01]: [Say] (KU_iJIpcpXi) a: 4\n[00:00:02]: [Say] (KU_iJIpcpXi) a: 5\n"), 0644))
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		done <- TailChatLog(ctx, path, TailOptions{Checkpoint: checkpoint, PollInterval: 10 * time.Millisecond}, func(log ChatLog, cp ChatLogCheckpoint) error {
+			logs <- log
+			return io.EOF
+		})
+	}()
+	assert.EqualValues(t, "5", next())
+	assert.ErrorIs(t, <-done, io.EOF)
+}
+
+func TestTailChatLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server_chat_log.txt")
+	assert.Nil(t, os.WriteFile(path, []byte("[00:00:01]: [Say] (KU_iJIpcpXi) a: 1\n"), 0644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logs := make(chan ChatLog, 16)
+	go func() {
+		_ = TailChatLog(ctx, path, TailOptions{PollInterval: 200 * time.Millisecond}, func(log ChatLog, cp ChatLogCheckpoint) error {
+			logs <- log
+			return nil
+		})
+	}()
+
+	next := func() ChatLog {
+		select {
+		case log := <-logs:
+			return log
+		case <-ctx.Done():
+			return ChatLog{}
+		}
+	}
+	assert.EqualValues(t, "1", next().Msg)
+
+	// final lines appended, then renamed and recreated within one poll interval
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.Nil(t, err)
+	_, _ = file.WriteString("[00:00:02]: [Leave Announcement] a\n")
+	assert.Nil(t, file.Close())
+	assert.Nil(t, os.Rename(path, path+".1"))
+	assert.Nil(t, os.WriteFile(path, []byte("[00:00:01]: [Say] (KU_iJIpcpXi) a: 3\n"), 0644))
+
+	assert.EqualValues(t, ChatTypeLeave, next().Type)
+	assert.EqualValues(t, "3", next().Msg)
+}
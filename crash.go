@@ -0,0 +1,109 @@
+package dstparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LuaCrash represents a lua error block in server_log.txt
+type LuaCrash struct {
+	// elapsed time since server started
+	Offset time.Duration `mapstructure:"offset"`
+	// the error message, egs. attempt to index a nil value
+	Message string `mapstructure:"message"`
+	// script file and line number where the error raised, line is 0 for traceback without error message
+	File string `mapstructure:"file"`
+	Line int    `mapstructure:"line"`
+	// the stack traceback lines, without leading whitespaces
+	Traceback []string `mapstructure:"traceback"`
+	// all raw lines of this error block
+	Lines []string `mapstructure:"lines"`
+	// workshop ids of mods appear in the error, in order of appearance, egs. 1185229307
+	ModIds []string `mapstructure:"mod_ids"`
+	// mods found in catalog for ModIds
+	Mods []ModInfo `mapstructure:"mods"`
+}
+
+// Culprit returns the workshop id of mod that most likely caused the crash,
+// which is the first mod found in the error message or traceback, empty if no mod involved.
+func (c LuaCrash) Culprit() string {
+	if len(c.ModIds) == 0 {
+		return ""
+	}
+	return c.ModIds[0]
+}
+
+var workshopPattern = regexp.MustCompile(`workshop-(\d+)`)
+
+// ExtractLuaCrashes finds the lua errors and stack tracebacks in server_log.txt records, groups their lines,
+// and attributes them to workshop mods. catalog is keyed by workshop id, it is optional and used to fill LuaCrash.Mods.
+func ExtractLuaCrashes(records []ServerLogRecord, catalog map[string]ModInfo) []LuaCrash {
+	var (
+		crashes []LuaCrash
+		current *LuaCrash
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.ModIds = crashModIds(current.Lines)
+		for _, id := range current.ModIds {
+			if info, ok := catalog[id]; ok {
+				current.Mods = append(current.Mods, info)
+			}
+		}
+		crashes = append(crashes, *current)
+		current = nil
+	}
+
+	for _, record := range records {
+		switch {
+		case record.Kind == ServerLogKindScriptError:
+			flush()
+			current = &LuaCrash{
+				Offset:  record.Offset,
+				Message: record.Fields["msg"],
+				File:    record.Fields["file"],
+				Lines:   []string{record.Raw},
+			}
+			current.Line, _ = strconv.Atoi(record.Fields["line"])
+		case record.Kind == ServerLogKindLuaError:
+			// traceback follows the error message, or stands alone
+			if current == nil || len(current.Traceback) > 0 {
+				flush()
+				current = &LuaCrash{Offset: record.Offset}
+			}
+			current.Lines = append(current.Lines, record.Raw)
+		case current != nil && len(record.Time) == 0:
+			// continuation lines
+			current.Lines = append(current.Lines, record.Raw)
+			if line := strings.TrimSpace(record.Raw); len(line) > 0 {
+				current.Traceback = append(current.Traceback, line)
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return crashes
+}
+
+// crashModIds returns workshop ids appear in lines, deduplicated in order
+func crashModIds(lines []string) []string {
+	var ids []string
+	seen := make(map[string]struct{})
+	for _, line := range lines {
+		for _, match := range workshopPattern.FindAllStringSubmatch(line, -1) {
+			if _, ok := seen[match[1]]; ok {
+				continue
+			}
+			seen[match[1]] = struct{}{}
+			ids = append(ids, match[1])
+		}
+	}
+	return ids
+}
@@ -0,0 +1,47 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExtractLuaCrashes(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/log/server_log.txt")
+	assert.Nil(t, err)
+	records, err := ParseServerLog(bytes)
+	assert.Nil(t, err)
+
+	modinfo, err := os.ReadFile("testdata/workshop/1185229307/modinfo.lua")
+	assert.Nil(t, err)
+	info, err := ParseModInfo(modinfo)
+	assert.Nil(t, err)
+
+	crashes := ExtractLuaCrashes(records, map[string]ModInfo{"1185229307": info})
+	assert.Len(t, crashes, 1)
+
+	crash := crashes[0]
+	t.Logf("%+v", crash)
+	assert.EqualValues(t, 9*time.Minute+12*time.Second, crash.Offset)
+	assert.EqualValues(t, "attempt to index a nil value (field 'components')", crash.Message)
+	assert.EqualValues(t, "../mods/workshop-1185229307/modmain.lua", crash.File)
+	assert.Equal(t, 123, crash.Line)
+	assert.Len(t, crash.Lines, 5)
+	assert.Len(t, crash.Traceback, 3)
+	assert.EqualValues(t, "1185229307", crash.Culprit())
+	assert.Len(t, crash.Mods, 1)
+	assert.EqualValues(t, info.Name, crash.Mods[0].Name)
+}
+
+func TestExtractLuaCrashesWithoutMod(t *testing.T) {
+	records, err := ParseServerLog([]byte(`[00:00:01]: LUA ERROR stack traceback:
+    scripts/entityscript.lua:1067 in (method) PushEvent (Lua) <1055-1077>
+[00:00:02]: Shutting down`))
+	assert.Nil(t, err)
+
+	crashes := ExtractLuaCrashes(records, nil)
+	assert.Len(t, crashes, 1)
+	assert.Empty(t, crashes[0].Culprit())
+	assert.Len(t, crashes[0].Traceback, 1)
+}
@@ -0,0 +1,280 @@
+package dstparser
+
+import (
+	"gopkg.in/ini.v1"
+	"reflect"
+	"strings"
+)
+
+// InIDocument is a line based ini document, it keeps comments, blank lines and the layout of untouched lines,
+// only the lines of modified keys will be rewritten.
+type InIDocument struct {
+	lines []string
+}
+
+// ParseInIDocument loads ini data into InIDocument
+func ParseInIDocument(data []byte) *InIDocument {
+	if len(data) == 0 {
+		return &InIDocument{}
+	}
+	return &InIDocument{lines: splitInILines(string(data))}
+}
+
+// splitInILines splits data into lines with line endings
+func splitInILines(data string) []string {
+	lines := strings.SplitAfter(data, "\n")
+	if len(lines) > 1 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Bytes returns the content of the document
+func (d *InIDocument) Bytes() []byte {
+	return []byte(strings.Join(d.lines, ""))
+}
+
+// iniLine parses a line as section header or key-value pair, returns empty strings for comments and blank lines.
+func iniLine(line string) (section string, key string, valueStart int, valueEnd int) {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] == ';' || trimmed[0] == '#' {
+		return "", "", 0, 0
+	}
+	if surround(trimmed, "[", "]") {
+		return strings.TrimSpace(trimmed[1 : len(trimmed)-1]), "", 0, 0
+	}
+	eq := strings.IndexAny(line, "=:")
+	if eq < 0 {
+		return "", "", 0, 0
+	}
+	key = strings.TrimSpace(line[:eq])
+	valueStart = eq + 1
+	for valueStart < len(line) && (line[valueStart] == ' ' || line[valueStart] == '\t') {
+		valueStart++
+	}
+	valueEnd = len(strings.TrimRight(line, " \t\r\n"))
+	if valueEnd < valueStart {
+		return "", key, valueStart, valueStart
+	}
+
+	// quoted value ends at the last quote, otherwise at the inline comment, same as go-ini
+	value := line[valueStart:valueEnd]
+	if quote := iniQuote(value); len(quote) > 0 {
+		if pos := strings.LastIndex(value[len(quote):], quote); pos >= 0 {
+			return "", key, valueStart, valueStart + len(quote) + pos + len(quote)
+		}
+	} else if i := strings.IndexAny(value, "#;"); i >= 0 {
+		valueEnd = valueStart + len(strings.TrimRight(value[:i], " \t"))
+	}
+	return "", key, valueStart, valueEnd
+}
+
+// iniQuote returns the quote which value starts with, only the quotes written by go-ini are recognized
+func iniQuote(value string) string {
+	if len(value) > 3 && strings.HasPrefix(value, `"""`) {
+		return `"""`
+	}
+	if strings.HasPrefix(value, "`") {
+		return "`"
+	}
+	return ""
+}
+
+// iniValue quotes value in the same way as go-ini does when writing
+func iniValue(value string) string {
+	switch {
+	case strings.ContainsAny(value, "\n`"):
+		return `"""` + value + `"""`
+	case strings.ContainsAny(value, "#;"):
+		return "`" + value + "`"
+	case len(strings.TrimSpace(value)) != len(value):
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// iniUnquote returns the value without quotes
+func iniUnquote(value string) string {
+	if quote := iniQuote(value); len(quote) > 0 && len(value) >= 2*len(quote) && strings.HasSuffix(value, quote) {
+		return value[len(quote) : len(value)-len(quote)]
+	}
+	for _, quote := range []string{`"`, "'"} {
+		if len(value) >= 2 && surround(value, quote, quote) {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// entry returns the value range of the key-value pair at line idx. A quoted value without closing quote in the line
+// continues until the line containing the closing quote as go-ini reads, last is the index of the line where
+// the value ends and end is the end of value in that line.
+func (d *InIDocument) entry(idx int) (start, last, end int) {
+	line := d.lines[idx]
+	_, _, start, end = iniLine(line)
+	quote := iniQuote(line[start:])
+	if len(quote) == 0 || strings.Contains(line[start+len(quote):], quote) {
+		return start, idx, end
+	}
+	for i := idx + 1; i < len(d.lines); i++ {
+		if pos := strings.LastIndex(d.lines[i], quote); pos >= 0 {
+			return start, i, pos + len(quote)
+		}
+	}
+	// missing closing quote, the value takes the rest of document
+	last = len(d.lines) - 1
+	return start, last, len(strings.TrimRight(d.lines[last], "\r\n"))
+}
+
+// find returns the line index of the key, and the index of the last line belonged to the section,
+// section index is -1 if section not found.
+func (d *InIDocument) find(section, key string) (keyIdx int, sectionEnd int) {
+	keyIdx, sectionEnd = -1, -1
+	current := ini.DefaultSection
+	for i := 0; i < len(d.lines); i++ {
+		sec, k, _, _ := iniLine(d.lines[i])
+		if len(sec) > 0 {
+			current = sec
+			if current == section {
+				sectionEnd = i
+			}
+			continue
+		}
+		if len(k) == 0 {
+			continue
+		}
+		idx := i
+		// skip the continuation lines of multi-line value
+		_, i, _ = d.entry(i)
+		if current != section {
+			continue
+		}
+		sectionEnd = i
+		if k == key && keyIdx < 0 {
+			keyIdx = idx
+		}
+	}
+	return keyIdx, sectionEnd
+}
+
+// Get returns the value of key in section
+func (d *InIDocument) Get(section, key string) (string, bool) {
+	idx, _ := d.find(section, key)
+	if idx < 0 {
+		return "", false
+	}
+	start, last, end := d.entry(idx)
+	if last == idx {
+		return iniUnquote(d.lines[idx][start:end]), true
+	}
+	value := d.lines[idx][start:] + strings.Join(d.lines[idx+1:last], "") + d.lines[last][:end]
+	return iniUnquote(value), true
+}
+
+// Set sets the value of key in section, only the value part of the line is replaced if the key exists and
+// the inline comment is kept, otherwise the key is appended to the end of section, and the section is appended
+// to the end of document if not exist. Value is quoted as go-ini does if it contains comment chars, surrounding
+// spaces or line breaks, multi-line values are replaced as a whole.
+func (d *InIDocument) Set(section, key, value string) {
+	value = iniValue(value)
+	idx, sectionEnd := d.find(section, key)
+	if idx >= 0 {
+		start, last, end := d.entry(idx)
+		d.splice(idx, last+1, d.lines[idx][:start]+value+d.lines[last][end:])
+		return
+	}
+
+	newline := d.newline()
+	kv := key + " = " + value + newline
+	if sectionEnd >= 0 {
+		d.ensureLineEnd(sectionEnd, newline)
+		d.splice(sectionEnd+1, sectionEnd+1, kv)
+		return
+	}
+
+	if len(d.lines) > 0 {
+		d.ensureLineEnd(len(d.lines)-1, newline)
+		if strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+			d.lines = append(d.lines, newline)
+		}
+	}
+	d.lines = append(d.lines, "["+section+"]"+newline)
+	d.lines = append(d.lines, splitInILines(kv)...)
+}
+
+// Delete removes the key in section, including the continuation lines of multi-line value
+func (d *InIDocument) Delete(section, key string) {
+	idx, _ := d.find(section, key)
+	if idx < 0 {
+		return
+	}
+	_, last, _ := d.entry(idx)
+	d.splice(idx, last+1, "")
+}
+
+// splice replaces lines from index i to j with the lines of content
+func (d *InIDocument) splice(i, j int, content string) {
+	var lines []string
+	if len(content) > 0 {
+		lines = splitInILines(content)
+	}
+	d.lines = append(d.lines[:i], append(lines, d.lines[j:]...)...)
+}
+
+// newline returns the line ending used by document
+func (d *InIDocument) newline() string {
+	for _, line := range d.lines {
+		if strings.HasSuffix(line, "\r\n") {
+			return "\r\n"
+		}
+	}
+	return "\n"
+}
+
+func (d *InIDocument) ensureLineEnd(idx int, newline string) {
+	if !strings.HasSuffix(d.lines[idx], "\n") {
+		d.lines[idx] += newline
+	}
+}
+
+// EditClusterInI applies the changes of config to the original cluster.ini data field by field,
+// keys whose value are not changed keep byte-identical, as well as comments, blank lines and ordering.
+func EditClusterInI(data []byte, config ClusterConfig) ([]byte, error) {
+	var origin ClusterConfig
+	return editInI(data, &origin, &config)
+}
+
+// EditServerInI applies the changes of config to the original server.ini data field by field,
+// keys whose value are not changed keep byte-identical, as well as comments, blank lines and ordering.
+func EditServerInI(data []byte, config ServerConfig) ([]byte, error) {
+	var origin ServerConfig
+	return editInI(data, &origin, &config)
+}
+
+func editInI(data []byte, origin, config any) ([]byte, error) {
+	if err := ini.MapTo(origin, data); err != nil {
+		return nil, err
+	}
+
+	reflected := ini.Empty()
+	if err := reflected.ReflectFrom(config); err != nil {
+		return nil, err
+	}
+
+	doc := ParseInIDocument(data)
+	originValue, configValue := reflect.ValueOf(origin).Elem(), reflect.ValueOf(config).Elem()
+	for _, key := range iniKeysOf(configValue.Type()) {
+		if reflect.DeepEqual(originValue.FieldByIndex(key.Index).Interface(), configValue.FieldByIndex(key.Index).Interface()) {
+			continue
+		}
+		section := reflected.Section(key.Section)
+		if !section.HasKey(key.Name) {
+			// omitted empty value
+			doc.Delete(key.Section, key.Name)
+			continue
+		}
+		doc.Set(key.Section, key.Name, section.Key(key.Name).String())
+	}
+
+	return doc.Bytes(), nil
+}
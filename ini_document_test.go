@@ -0,0 +1,134 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInIDocument(t *testing.T) {
+	const data = "; comment\r\n[NETWORK]\r\nserver_port   =   11000\r\n\r\n[SHARD]\r\nis_master = true\r\n"
+	doc := ParseInIDocument([]byte(data))
+
+	port, ok := doc.Get("NETWORK", "server_port")
+	assert.True(t, ok)
+	assert.EqualValues(t, "11000", port)
+	assert.EqualValues(t, data, string(doc.Bytes()))
+
+	doc.Set("NETWORK", "server_port", "11001")
+	doc.Set("SHARD", "name", "Caves")
+	doc.Set("STEAM", "master_server_port", "27019")
+	doc.Delete("SHARD", "is_master")
+
+	assert.EqualValues(t, "; comment\r\n[NETWORK]\r\nserver_port   =   11001\r\n\r\n[SHARD]\r\nname = Caves\r\n\r\n[STEAM]\r\nmaster_server_port = 27019\r\n", string(doc.Bytes()))
+}
+
+func TestInIDocumentQuote(t *testing.T) {
+	const data = "[GAMEPLAY]\nmax_players = 6 ; note\n[NETWORK]\ncluster_description = `a # b`  # note\n"
+	doc := ParseInIDocument([]byte(data))
+
+	players, _ := doc.Get("GAMEPLAY", "max_players")
+	assert.EqualValues(t, "6", players)
+	description, _ := doc.Get("NETWORK", "cluster_description")
+	assert.EqualValues(t, "a # b", description)
+
+	// inline comments are kept, values are quoted as go-ini does
+	doc.Set("GAMEPLAY", "max_players", "8")
+	doc.Set("NETWORK", "cluster_description", "join #1 server; fun")
+	doc.Set("NETWORK", "cluster_name", " padded ")
+	assert.EqualValues(t, "[GAMEPLAY]\nmax_players = 8 ; note\n[NETWORK]\ncluster_description = `join #1 server; fun`  # note\ncluster_name = \" padded \"\n", string(doc.Bytes()))
+
+	description, _ = doc.Get("NETWORK", "cluster_description")
+	assert.EqualValues(t, "join #1 server; fun", description)
+}
+
+func TestInIDocumentMultiline(t *testing.T) {
+	const data = "[NETWORK]\ncluster_description = \"\"\"first line\n[not a section]\nkey = not a key\"\"\" ; note\ncluster_name = local\n"
+	doc := ParseInIDocument([]byte(data))
+
+	description, ok := doc.Get("NETWORK", "cluster_description")
+	assert.True(t, ok)
+	assert.EqualValues(t, "first line\n[not a section]\nkey = not a key", description)
+	_, ok = doc.Get("not a section", "key")
+	assert.False(t, ok)
+
+	// continuation lines are replaced as a whole
+	doc.Set("NETWORK", "cluster_description", "one line")
+	doc.Set("NETWORK", "cluster_password", "secret")
+	assert.EqualValues(t, "[NETWORK]\ncluster_description = one line ; note\ncluster_name = local\ncluster_password = secret\n", string(doc.Bytes()))
+
+	doc.Set("NETWORK", "cluster_description", "two\nlines")
+	doc.Set("NETWORK", "cluster_name", "edited")
+	assert.EqualValues(t, "[NETWORK]\ncluster_description = \"\"\"two\nlines\"\"\" ; note\ncluster_name = edited\ncluster_password = secret\n", string(doc.Bytes()))
+
+	config, err := ParseClusterInI(doc.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "two\nlines", config.NetWork.ClusterDescription)
+	assert.EqualValues(t, "edited", config.NetWork.ClusterName)
+
+	doc.Delete("NETWORK", "cluster_description")
+	assert.EqualValues(t, "[NETWORK]\ncluster_name = edited\ncluster_password = secret\n", string(doc.Bytes()))
+}
+
+func TestEditClusterInI(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/cluster.ini")
+	assert.Nil(t, err)
+	config, err := ParseClusterInI(bytes)
+	assert.Nil(t, err)
+
+	// nothing changed
+	edited, err := EditClusterInI(bytes, config)
+	assert.Nil(t, err)
+	assert.EqualValues(t, string(bytes), string(edited))
+
+	config.GamePlay.MaxPlayers = 12
+	config.NetWork.ClusterName = "Edited"
+	edited, err = EditClusterInI(bytes, config)
+	assert.Nil(t, err)
+
+	originLines := strings.Split(string(bytes), "\n")
+	editedLines := strings.Split(string(edited), "\n")
+	assert.Equal(t, len(originLines), len(editedLines))
+	for i := range originLines {
+		switch {
+		case strings.HasPrefix(originLines[i], "max_players"):
+			assert.EqualValues(t, "max_players = 12", editedLines[i])
+		case strings.HasPrefix(originLines[i], "cluster_name"):
+			assert.EqualValues(t, "cluster_name = Edited", editedLines[i])
+		default:
+			assert.EqualValues(t, originLines[i], editedLines[i])
+		}
+	}
+
+	reparsed, err := ParseClusterInI(edited)
+	assert.Nil(t, err)
+	assert.EqualValues(t, config, reparsed)
+
+	// values with comment chars survive the round trip
+	config.NetWork.ClusterDescription = "join #1 server; fun"
+	edited, err = EditClusterInI(bytes, config)
+	assert.Nil(t, err)
+	reparsed, err = ParseClusterInI(edited)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "join #1 server; fun", reparsed.NetWork.ClusterDescription)
+}
+
+func TestEditServerInI(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/server.cave.ini")
+	assert.Nil(t, err)
+	config, err := ParseServerInI(bytes)
+	assert.Nil(t, err)
+
+	config.Shard.ID = "2"
+	config.Account.EncodeUserPath = true
+	edited, err := EditServerInI(bytes, config)
+	assert.Nil(t, err)
+	assert.Contains(t, string(edited), "\nid = 2\n")
+	assert.Contains(t, string(edited), "\nencode_user_path = true\n")
+
+	reparsed, err := ParseServerInI(edited)
+	assert.Nil(t, err)
+	assert.EqualValues(t, config, reparsed)
+	assert.True(t, strings.HasPrefix(string(edited), "[NETWORK]\nserver_port = 11001\n"))
+}
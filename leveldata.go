@@ -2,9 +2,13 @@ package dstparser
 
 import (
 	"bytes"
-	"github.com/mitchellh/mapstructure"
+	"fmt"
 	lua "github.com/yuin/gopher-lua"
-	"text/template"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -44,6 +48,14 @@ type LevelDataOverrides struct {
 	RequiredSetPieces   []string            `mapstructure:"required_setpieces"`
 	Substitutes         []string            `mapstructure:"substitutes"`
 	BackGroundNodeRange []float64           `mapstructure:"background_node_range"`
+
+	// top-level keys not modelled above, egs. set_pieces, ordered_story_setpieces of world-gen mods,
+	// lua tables are converted to []any or map[string]any
+	Extra map[string]any `mapstructure:",remain"`
+
+	// modelled top-level keys present in the parsed file in alphabetical order, nil if not parsed,
+	// egs. an old forest file may have no playstyle. It is not a key of leveldataoverride.lua itself.
+	Keys []string `mapstructure:"-"`
 }
 
 // ParseLevelDataOverrides parses the leveldataoverrides.lua, returns LevelDataOverrides information
@@ -87,9 +99,13 @@ func ParseLevelDataOverrides(luaScript []byte) (LevelDataOverrides, error) {
 		overrideTableL.GetTable("overrides").T().ForEach(func(name lua.LValue, value lua.LValue) {
 			levelDataOverrides.Overrides = append(levelDataOverrides.Overrides, LevelOverrideItem{
 				Name:  name.String(),
-				Value: judgeOptionValue(value),
+				Value: luaToGo(value),
 			})
 		})
+		// the game writes overrides in the order of key
+		sort.Slice(levelDataOverrides.Overrides, func(i, j int) bool {
+			return levelDataOverrides.Overrides[i].Name < levelDataOverrides.Overrides[j].Name
+		})
 	}
 
 	// random_set_pieces
@@ -127,106 +143,347 @@ func ParseLevelDataOverrides(luaScript []byte) (LevelDataOverrides, error) {
 		})
 	}
 
+	// keys not modelled
+	levelDataOverrides.Keys = []string{}
+	overrideTable.ForEach(func(key lua.LValue, value lua.LValue) {
+		if key.Type() != lua.LTString {
+			return
+		}
+		if _, ok := levelDataKnownKeys[key.String()]; ok {
+			levelDataOverrides.Keys = append(levelDataOverrides.Keys, key.String())
+			return
+		}
+		if levelDataOverrides.Extra == nil {
+			levelDataOverrides.Extra = make(map[string]any)
+		}
+		levelDataOverrides.Extra[key.String()] = luaToGo(value)
+	})
+	sort.Strings(levelDataOverrides.Keys)
+
 	return levelDataOverrides, nil
 }
 
-const masterLevelOverrides = `
-return {
-  desc={{ t .desc }},
-  hideminimap={{ t .hideminimap }},
-  id={{ t .id }},
-  location={{ t .location }},
-  max_playlist_position={{ t .max_playlist_position }},
-  min_playlist_position={{ t .min_playlist_position }},
-  name={{ t .name }},
-  numrandom_set_pieces={{ t .numrandom_set_pieces }},
-  override_level_string={{ t .override_level_string }},
-  overrides={ {{ range $index, $value := .overrides }}
-    {{$value.Name }}={{ t $value.Value }}, {{ end }}
-  },
-  playstyle={{ t .playstyle }},
-  random_set_pieces={ {{ range $index, $value := .random_set_pieces }}
-    {{ t $value }}, {{ end }}
-  },
-  required_prefabs={ {{ range $index, $value := .required_prefabs }}
-    {{ t $value }}, {{ end }}
-  },
-  required_setpieces={ {{ range $index, $value := .required_setpieces }}
-    {{ t $value }}, {{ end }}
-  },
-  settings_desc={{ t .settings_desc }},
-  settings_id={{ t .settings_id }},
-  settings_name={{ t .settings_name }},
-  substitutes={ {{ range $index, $value := .substitutes }}
-    {{ t $value }}, {{ end }}
-  },
-  version={{ t .version }},
-  worldgen_desc={{ t .worldgen_desc }},
-  worldgen_id={{ t .worldgen_id }},
-  worldgen_name={{ t .worldgen_name }},
-}
-`
-
-const caveLevelOverrides = `
-return {
-  background_node_range={ {{ range $index, $value := .background_node_range }}
-    {{ t $value }}, {{ end }}
-  },
-  desc={{ t .desc }},
-  hideminimap={{ t .hideminimap }},
-  id={{ t .id }},
-  location={{ t .location }},
-  max_playlist_position={{ t .max_playlist_position }},
-  min_playlist_position={{ t .min_playlist_position }},
-  name={{ t .name }},
-  numrandom_set_pieces={{ t .numrandom_set_pieces }},
-  override_level_string={{ t .override_level_string }},
-  overrides={ {{ range $index, $value := .overrides }}
-    {{$value.Name }}={{ t $value.Value }}, {{ end }}
-  },
-  required_prefabs={  {{ range $index, $value := .required_prefabs }}
-    {{ t $value }}, {{ end }}
-  },
-  settings_desc={{ t .settings_desc }},
-  settings_id={{ t .settings_id }},
-  settings_name={{ t .settings_name }},
-  substitutes={ {{ range $index, $value := .required_prefabs }}
-    {{ t $value }}, {{ end }}
-  },
-  version={{ t .version }},
-  worldgen_desc={{ t .worldgen_desc }},
-  worldgen_id={{ t .worldgen_id }},
-  worldgen_name={{ t .worldgen_name }}
-}
-`
+// HasKey reports whether the modelled top-level key is present in the parsed file, always false if not parsed
+func (l LevelDataOverrides) HasKey(key string) bool {
+	i := sort.SearchStrings(l.Keys, key)
+	return i < len(l.Keys) && l.Keys[i] == key
+}
+
+// levelDataKeys is the keys written to leveldataoverride.lua in order, optional keys are only written if not empty,
+// keys of location are always written like the game does
+var (
+	levelDataKeys = []string{
+		"background_node_range", "desc", "hideminimap", "id", "location", "max_playlist_position",
+		"min_playlist_position", "name", "numrandom_set_pieces", "override_level_string", "overrides",
+		"playstyle", "random_set_pieces", "required_prefabs", "required_setpieces", "settings_desc", "settings_id",
+		"settings_name", "substitutes", "version", "worldgen_desc", "worldgen_id", "worldgen_name",
+	}
+	levelDataKnownKeys = func() map[string]struct{} {
+		known := make(map[string]struct{}, len(levelDataKeys))
+		for _, key := range levelDataKeys {
+			known[key] = struct{}{}
+		}
+		return known
+	}()
+	levelDataOptionalKeys = map[string]struct{}{
+		"background_node_range": {},
+		"playstyle":             {},
+		"random_set_pieces":     {},
+		"required_setpieces":    {},
+	}
+	levelDataLocationKeys = map[string][]string{
+		LocationForest:    {"playstyle", "random_set_pieces", "required_setpieces"},
+		LocationCave:      {"background_node_range"},
+		LocationLavaArena: {"background_node_range"},
+		LocationQuagmire:  {"background_node_range"},
+	}
+)
+
+// levelDataKeyOf returns the key in leveldataoverride.lua of field with mapstructure tag,
+// tags of playlist positions differ from the keys written by the game.
+func levelDataKeyOf(tag string) string {
+	switch tag {
+	case "max_playerlist_position":
+		return "max_playlist_position"
+	case "min_playerlist_position":
+		return "min_playlist_position"
+	}
+	return tag
+}
+
+// ToLevelDataOverridesLua converts LevelDataOverrides to leveldataoverride.lua. If Keys is not nil, exactly the keys
+// in Keys are written, plus keys set to non-zero values afterwards. Otherwise the keys written depend on Location
+// as the game does, egs. playstyle and set pieces for forest, background_node_range for cave.
+// Keys are written in alphabetical order including keys in Extra, overrides are written in the order of the slice.
+// Parsing the output gives the same LevelDataOverrides.
+func ToLevelDataOverridesLua(overrides LevelDataOverrides) ([]byte, error) {
+	values := map[string]any{
+		"background_node_range": overrides.BackGroundNodeRange,
+		"desc":                  overrides.Desc,
+		"hideminimap":           overrides.HideMiniMap,
+		"id":                    overrides.Id,
+		"location":              overrides.Location,
+		"max_playlist_position": overrides.MaxPlayerListPosition,
+		"min_playlist_position": overrides.MinPlayerListPosition,
+		"name":                  overrides.Name,
+		"numrandom_set_pieces":  overrides.NumRandomSetPieces,
+		"override_level_string": overrides.OverrideLevelString,
+		"overrides":             overrides.Overrides,
+		"playstyle":             overrides.PlayStyle,
+		"random_set_pieces":     overrides.RandomSetPieces,
+		"required_prefabs":      overrides.RequiredPrefabs,
+		"required_setpieces":    overrides.RequiredSetPieces,
+		"settings_desc":         overrides.SettingDesc,
+		"settings_id":           overrides.SettingId,
+		"settings_name":         overrides.SettingName,
+		"substitutes":           overrides.Substitutes,
+		"version":               overrides.Version,
+		"worldgen_desc":         overrides.WorldGenDesc,
+		"worldgen_id":           overrides.WorldGenId,
+		"worldgen_name":         overrides.WorldGenName,
+	}
+
+	required := make(map[string]struct{})
+	for _, key := range levelDataLocationKeys[overrides.Location] {
+		required[key] = struct{}{}
+	}
+
+	keys := append([]string(nil), levelDataKeys...)
+	for key, value := range overrides.Extra {
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+			values[key] = value
+		}
+	}
+	sort.Strings(keys)
+
+	var entries []string
+	for _, key := range keys {
+		value := values[key]
+		if _, known := levelDataKnownKeys[key]; known && overrides.Keys != nil {
+			if !overrides.HasKey(key) && reflect.ValueOf(value).IsZero() {
+				continue
+			}
+		} else if _, optional := levelDataOptionalKeys[key]; optional {
+			if _, ok := required[key]; !ok && reflect.ValueOf(value).IsZero() {
+				continue
+			}
+		}
+
+		var entry string
+		switch value := value.(type) {
+		case []LevelOverrideItem:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				v, err := luaLiteral(item.Value, "    ")
+				if err != nil {
+					return nil, fmt.Errorf("override %s: %w", item.Name, err)
+				}
+				items = append(items, luaKey(item.Name)+"="+v)
+			}
+			entry = luaTable(items, true, "  ")
+		case []string:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, luaString(item))
+			}
+			entry = luaTable(items, len(items) > 1, "  ")
+		case []float64:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, luaNumber(item))
+			}
+			entry = luaTable(items, false, "  ")
+		default:
+			v, err := luaLiteral(value, "  ")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			entry = v
+		}
+		entries = append(entries, "  "+key+"="+entry)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString("return {\n")
+	buffer.WriteString(strings.Join(entries, ",\n"))
+	buffer.WriteString("\n}\n")
+	return buffer.Bytes(), nil
+}
 
 // ToMasterLevelDataOverridesLua converts LevelDataOverrides to lua script
+//
+// Deprecated: use ToLevelDataOverridesLua, which writes the keys according to Location.
 func ToMasterLevelDataOverridesLua(overrides LevelDataOverrides) ([]byte, error) {
-	return toLevelDataOverridesLua(masterLevelOverrides, overrides)
+	return ToLevelDataOverridesLua(overrides)
 }
 
 // ToCaveLevelDataOverridesLua converts LevelDataOverrides to lua script
+//
+// Deprecated: use ToLevelDataOverridesLua, which writes the keys according to Location.
 func ToCaveLevelDataOverridesLua(overrides LevelDataOverrides) ([]byte, error) {
-	return toLevelDataOverridesLua(caveLevelOverrides, overrides)
+	return ToLevelDataOverridesLua(overrides)
 }
 
-func toLevelDataOverridesLua(tmpl string, val any) ([]byte, error) {
-	var data map[string]any
-	if err := mapstructure.Decode(val, &data); err != nil {
-		return nil, err
+// luaTable formats items as lua table constructor, one item per line if multiline,
+// indent is the indentation of the line where the table starts
+func luaTable(items []string, multiline bool, indent string) string {
+	if len(items) == 0 {
+		return "{  }"
+	}
+	if !multiline {
+		return "{ " + strings.Join(items, ", ") + " }"
 	}
-	templ, err := template.New("leveloverrides").
-		Funcs(map[string]any{"t": t}).
-		Parse(tmpl)
+	return "{\n" + indent + "  " + strings.Join(items, ",\n"+indent+"  ") + "\n" + indent + "}"
+}
 
-	if err != nil {
-		return nil, err
+// luaLiteral formats value as lua literal, []any and map[string]any are formatted as tables,
+// keys of map which are integers are written as number keys
+func luaLiteral(value any, indent string) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		return luaString(value), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case int:
+		return strconv.Itoa(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return luaNumber(value), nil
+	case []any:
+		items := make([]string, 0, len(value))
+		multiline := false
+		for _, item := range value {
+			v, err := luaLiteral(item, indent+"  ")
+			if err != nil {
+				return "", err
+			}
+			items = append(items, v)
+			multiline = multiline || strings.Contains(v, "\n")
+		}
+		return luaTable(items, multiline, indent), nil
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, 0, len(value))
+		for _, key := range keys {
+			v, err := luaLiteral(value[key], indent+"  ")
+			if err != nil {
+				return "", err
+			}
+			items = append(items, luaKey(key)+"="+v)
+		}
+		return luaTable(items, true, indent), nil
+	case map[any]any:
+		keys := make([]any, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return luaKeyLess(keys[i], keys[j])
+		})
+		items := make([]string, 0, len(value))
+		for _, key := range keys {
+			k, err := luaAnyKey(key)
+			if err != nil {
+				return "", err
+			}
+			v, err := luaLiteral(value[key], indent+"  ")
+			if err != nil {
+				return "", err
+			}
+			items = append(items, k+"="+v)
+		}
+		return luaTable(items, true, indent), nil
 	}
+	return "", fmt.Errorf("unsupported lua value type %T", value)
+}
 
-	buffer := bytes.NewBuffer(nil)
-	if err := templ.Execute(buffer, data); err != nil {
-		return nil, err
+func luaNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// luaKey formats string table key, which is quoted if it is not a valid identifier or is a reserved word
+func luaKey(key string) string {
+	if _, reserved := luaReservedWords[key]; !reserved && luaIdentPattern.MatchString(key) {
+		return key
 	}
+	return "[" + luaString(key) + "]"
+}
 
-	return buffer.Bytes(), nil
+var luaIdentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var luaReservedWords = map[string]struct{}{
+	"and": {}, "break": {}, "do": {}, "else": {}, "elseif": {}, "end": {}, "false": {}, "for": {},
+	"function": {}, "goto": {}, "if": {}, "in": {}, "local": {}, "nil": {}, "not": {}, "or": {},
+	"repeat": {}, "return": {}, "then": {}, "true": {}, "until": {}, "while": {},
+}
+
+// luaAnyKey formats table key of any type, only string keys can be written as identifier
+func luaAnyKey(key any) (string, error) {
+	switch key := key.(type) {
+	case string:
+		return luaKey(key), nil
+	case bool:
+		return "[" + strconv.FormatBool(key) + "]", nil
+	case float64:
+		return "[" + luaNumber(key) + "]", nil
+	}
+	return "", fmt.Errorf("unsupported lua key type %T", key)
+}
+
+// luaKeyLess orders keys of different types by bool, number and string, then by value
+func luaKeyLess(a, b any) bool {
+	rank := func(key any) int {
+		switch key.(type) {
+		case bool:
+			return 0
+		case float64:
+			return 1
+		}
+		return 2
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra < rb
+	}
+	switch a := a.(type) {
+	case bool:
+		return !a && b.(bool)
+	case float64:
+		return a < b.(float64)
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+// luaString quotes s as lua string literal, escaping the characters lua 5.1 can not read in a quoted string
+func luaString(s string) string {
+	builder := &strings.Builder{}
+	builder.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			builder.WriteByte('\\')
+			builder.WriteByte(c)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(builder, "\\%03d", c)
+			} else {
+				builder.WriteByte(c)
+			}
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
 }
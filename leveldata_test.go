@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -58,3 +59,126 @@ func TestToCaveLevelDataOverridesLua(t *testing.T) {
 
 	fmt.Println(string(overridesLua))
 }
+
+func TestToLevelDataOverridesLua(t *testing.T) {
+	files, err := filepath.Glob("testdata/leveldata/*.lua")
+	assert.Nil(t, err)
+	files = append(files, "testdata/cluster/leveldataoverride.master.lua", "testdata/cluster/leveldataoverride.cave.lua")
+
+	for _, file := range files {
+		bytes, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		overrides, err := ParseLevelDataOverrides(bytes)
+		assert.Nil(t, err, file)
+
+		overridesLua, err := ToLevelDataOverridesLua(overrides)
+		assert.Nil(t, err, file)
+		reparsed, err := ParseLevelDataOverrides(overridesLua)
+		assert.Nil(t, err, file)
+		assert.Equal(t, overrides, reparsed, file)
+
+		rewritten, err := ToLevelDataOverridesLua(reparsed)
+		assert.Nil(t, err)
+		assert.Equal(t, string(overridesLua), string(rewritten), file)
+	}
+
+	for _, preset := range Presets() {
+		overrides, err := NewLevelDataOverrides(preset)
+		assert.Nil(t, err)
+		overridesLua, err := ToLevelDataOverridesLua(overrides)
+		assert.Nil(t, err)
+		reparsed, err := ParseLevelDataOverrides(overridesLua)
+		assert.Nil(t, err, preset)
+		// presets are not parsed, keys written are recorded in reparsed
+		assert.True(t, reparsed.HasKey("location"), preset)
+		reparsed.Keys = nil
+		assert.Equal(t, overrides, reparsed, preset)
+	}
+}
+
+func TestToLevelDataOverridesLuaLocation(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/leveldataoverride.cave.lua")
+	assert.Nil(t, err)
+	overrides, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+
+	overridesLua, err := ToLevelDataOverridesLua(overrides)
+	assert.Nil(t, err)
+	t.Log(string(overridesLua))
+	assert.Contains(t, string(overridesLua), "background_node_range={ 0, 1 }")
+	assert.NotContains(t, string(overridesLua), "playstyle")
+	assert.NotContains(t, string(overridesLua), " random_set_pieces=")
+	assert.Contains(t, string(overridesLua), `required_prefabs={ "multiplayer_portal" }`)
+
+	overrides.Desc = "quote \" back\\slash\nnew line\x01"
+	overridesLua, err = ToLevelDataOverridesLua(overrides)
+	assert.Nil(t, err)
+	assert.Contains(t, string(overridesLua), `desc="quote \" back\\slash\nnew line\001"`)
+	reparsed, err := ParseLevelDataOverrides(overridesLua)
+	assert.Nil(t, err)
+	assert.Equal(t, overrides.Desc, reparsed.Desc)
+}
+
+func TestParseLevelDataOverridesExtra(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/leveldata/modded.lua")
+	assert.Nil(t, err)
+	overrides, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2.0, overrides.Extra["numrandom_set_pieces_ocean"])
+	assert.Equal(t, []any{"TeleportatoRingLayout", "TeleportatoBoxLayout", "AdventurePortalLayout"}, overrides.Extra["ordered_story_setpieces"])
+	assert.Equal(t, map[string]any{
+		"count": 2.0,
+		"tasks": []any{"Make a pick", "Dig that rock", "Great Plains", "Squeltch"},
+	}, overrides.Extra["set_pieces"].(map[string]any)["ResurrectionStone"])
+	assert.Equal(t, map[any]any{1.0: 0.5, 3.0: 1.5}, overrides.Extra["weights"])
+	assert.NotContains(t, overrides.Extra, "overrides")
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "extra_tasks", Value: []any{"Mole Colony Deciduous", "Mole Colony Rocks"}})
+
+	overridesLua, err := ToLevelDataOverridesLua(overrides)
+	assert.Nil(t, err)
+	assert.Equal(t, string(bytes), string(overridesLua))
+}
+
+func TestToLevelDataOverridesLuaKeys(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/leveldata/keywords.lua")
+	assert.Nil(t, err)
+	overrides, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "end", Value: "x"})
+	assert.Equal(t, map[any]any{false: 0.0, true: 1.0, 1.5: "half", 2.0: "two", "1": "one", "and": 2.0, "plain": 3.0}, overrides.Extra["custom"])
+
+	// reserved words and keys of other types are written in brackets
+	overridesLua, err := ToLevelDataOverridesLua(overrides)
+	assert.Nil(t, err)
+	assert.Equal(t, string(bytes), string(overridesLua))
+}
+
+func TestToLevelDataOverridesLuaPresentKeys(t *testing.T) {
+	// forest file written by an old version without playstyle and set pieces
+	bytes, err := os.ReadFile("testdata/leveldata/forest_minimal.lua")
+	assert.Nil(t, err)
+	overrides, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+	assert.True(t, overrides.HasKey("numrandom_set_pieces"))
+	assert.False(t, overrides.HasKey("playstyle"))
+	assert.False(t, overrides.HasKey("random_set_pieces"))
+
+	overridesLua, err := ToLevelDataOverridesLua(overrides)
+	assert.Nil(t, err)
+	assert.Equal(t, string(bytes), string(overridesLua))
+
+	// keys set afterwards are written
+	overrides.PlayStyle = "survival"
+	overridesLua, err = ToLevelDataOverridesLua(overrides)
+	assert.Nil(t, err)
+	assert.Contains(t, string(overridesLua), `playstyle="survival"`)
+	assert.NotContains(t, string(overridesLua), " random_set_pieces")
+	assert.NotContains(t, string(overridesLua), "required_setpieces")
+
+	// keys depend on location if not parsed
+	overrides = LevelDataOverrides{Location: LocationForest}
+	overridesLua, err = ToLevelDataOverridesLua(overrides)
+	assert.Nil(t, err)
+	assert.Contains(t, string(overridesLua), `playstyle=""`)
+}
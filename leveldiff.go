@@ -0,0 +1,195 @@
+package dstparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// LevelDataChangeKind is the kind of change between two LevelDataOverrides
+type LevelDataChangeKind string
+
+const (
+	LevelDataAdded    LevelDataChangeKind = "added"
+	LevelDataRemoved  LevelDataChangeKind = "removed"
+	LevelDataModified LevelDataChangeKind = "modified"
+)
+
+// LevelDataChange is a change between two LevelDataOverrides
+type LevelDataChange struct {
+	Kind LevelDataChangeKind `mapstructure:"kind"`
+	// field name in leveldataoverride.lua, egs. id, overrides, random_set_pieces
+	Field string `mapstructure:"field"`
+	// override key if Field is overrides
+	Key string `mapstructure:"key"`
+	// nil if added
+	Old any `mapstructure:"old"`
+	// nil if removed
+	New any `mapstructure:"new"`
+}
+
+// Path returns the field with override key, egs. overrides.day
+func (c LevelDataChange) Path() string {
+	if len(c.Key) > 0 {
+		return c.Field + "." + c.Key
+	}
+	return c.Field
+}
+
+// String returns the change in one line, egs. overrides.day: "default" -> "longdusk"
+func (c LevelDataChange) String() string {
+	switch c.Kind {
+	case LevelDataAdded:
+		return fmt.Sprintf("%s: added %s", c.Path(), formatLevelDataValue(c.New))
+	case LevelDataRemoved:
+		return fmt.Sprintf("%s: removed %s", c.Path(), formatLevelDataValue(c.Old))
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.Path(), formatLevelDataValue(c.Old), formatLevelDataValue(c.New))
+}
+
+func formatLevelDataValue(value any) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// Diff returns the changes from l to other, in the order of fields of LevelDataOverrides.
+// Overrides are compared by key and reported in the order of key, set pieces and prefabs are compared as sets,
+// keys in Extra are reported with the key as Field.
+func (l LevelDataOverrides) Diff(other LevelDataOverrides) []LevelDataChange {
+	var changes []LevelDataChange
+
+	from, to := reflect.ValueOf(l), reflect.ValueOf(other)
+	for i := 0; i < from.NumField(); i++ {
+		field := from.Type().Field(i)
+		name := field.Tag.Get("mapstructure")
+		// not written in leveldataoverride.lua
+		if name == "-" {
+			continue
+		}
+
+		oldValue, newValue := from.Field(i).Interface(), to.Field(i).Interface()
+		switch value := oldValue.(type) {
+		case []LevelOverrideItem:
+			changes = append(changes, diffOverrides(value, newValue.([]LevelOverrideItem))...)
+		case []string:
+			changes = append(changes, diffStrings(name, value, newValue.([]string))...)
+		case map[string]any:
+			changes = append(changes, diffExtra(value, newValue.(map[string]any))...)
+		default:
+			if !reflect.DeepEqual(oldValue, newValue) {
+				changes = append(changes, LevelDataChange{Kind: LevelDataModified, Field: name, Old: oldValue, New: newValue})
+			}
+		}
+	}
+
+	return changes
+}
+
+func diffOverrides(from, to []LevelOverrideItem) []LevelDataChange {
+	values := make(map[string]any, len(from))
+	for _, item := range from {
+		values[item.Name] = item.Value
+	}
+
+	var changes []LevelDataChange
+	seen := make(map[string]struct{}, len(to))
+	for _, item := range to {
+		seen[item.Name] = struct{}{}
+		old, ok := values[item.Name]
+		switch {
+		case !ok:
+			changes = append(changes, LevelDataChange{Kind: LevelDataAdded, Field: "overrides", Key: item.Name, New: item.Value})
+		case !reflect.DeepEqual(old, item.Value):
+			changes = append(changes, LevelDataChange{Kind: LevelDataModified, Field: "overrides", Key: item.Name, Old: old, New: item.Value})
+		}
+	}
+	for _, item := range from {
+		if _, ok := seen[item.Name]; !ok {
+			changes = append(changes, LevelDataChange{Kind: LevelDataRemoved, Field: "overrides", Key: item.Name, Old: item.Value})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// diffExtra reports changes of top-level keys not modelled, in the order of key
+func diffExtra(from, to map[string]any) []LevelDataChange {
+	var changes []LevelDataChange
+	for key, value := range to {
+		old, ok := from[key]
+		switch {
+		case !ok:
+			changes = append(changes, LevelDataChange{Kind: LevelDataAdded, Field: key, New: value})
+		case !reflect.DeepEqual(old, value):
+			changes = append(changes, LevelDataChange{Kind: LevelDataModified, Field: key, Old: old, New: value})
+		}
+	}
+	for key, value := range from {
+		if _, ok := to[key]; !ok {
+			changes = append(changes, LevelDataChange{Kind: LevelDataRemoved, Field: key, Old: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// diffStrings reports removed items in the order of from, then added items in the order of to
+func diffStrings(field string, from, to []string) []LevelDataChange {
+	fromCount, toCount := make(map[string]int, len(from)), make(map[string]int, len(to))
+	for _, s := range from {
+		fromCount[s]++
+	}
+	for _, s := range to {
+		toCount[s]++
+	}
+
+	var changes []LevelDataChange
+	for _, s := range from {
+		if toCount[s] > 0 {
+			toCount[s]--
+			continue
+		}
+		changes = append(changes, LevelDataChange{Kind: LevelDataRemoved, Field: field, Old: s})
+	}
+	for _, s := range to {
+		if fromCount[s] > 0 {
+			fromCount[s]--
+			continue
+		}
+		changes = append(changes, LevelDataChange{Kind: LevelDataAdded, Field: field, New: s})
+	}
+
+	return changes
+}
+
+// FormatLevelDataDiff renders changes in unified diff style, from and to are the names of compared files, egs.
+//
+//	--- Master/leveldataoverride.lua
+//	+++ new
+//	-overrides.day = "default"
+//	+overrides.day = "longdusk"
+func FormatLevelDataDiff(from, to string, changes []LevelDataChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "--- %s\n+++ %s\n", from, to)
+	for _, change := range changes {
+		if change.Kind != LevelDataAdded {
+			fmt.Fprintf(builder, "-%s = %s\n", change.Path(), formatLevelDataValue(change.Old))
+		}
+		if change.Kind != LevelDataRemoved {
+			fmt.Fprintf(builder, "+%s = %s\n", change.Path(), formatLevelDataValue(change.New))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,54 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestLevelDataOverridesDiff(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/leveldataoverride.master.lua")
+	assert.Nil(t, err)
+	from, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+
+	assert.Empty(t, from.Diff(from))
+
+	to, err := NewLevelDataOverrides(PresetEndless)
+	assert.Nil(t, err)
+	to.Overrides = append(to.Overrides, LevelOverrideItem{Name: "my_mod_key", Value: true})
+	for i, item := range to.Overrides {
+		switch item.Name {
+		case "day":
+			to.Overrides[i].Value = "longdusk"
+		case "wormhole_prefab":
+			to.Overrides = append(to.Overrides[:i], to.Overrides[i+1:]...)
+		}
+	}
+	to.RandomSetPieces = append(to.RandomSetPieces[1:], "Maxwell8")
+
+	changes := from.Diff(to)
+	t.Log(FormatLevelDataDiff("leveldataoverride.lua", "new", changes))
+
+	assert.Contains(t, changes, LevelDataChange{Kind: LevelDataModified, Field: "worldgen_id", Old: "WILDERNESS", New: "ENDLESS"})
+	assert.Contains(t, changes, LevelDataChange{Kind: LevelDataModified, Field: "overrides", Key: "day", Old: "default", New: "longdusk"})
+	assert.Contains(t, changes, LevelDataChange{Kind: LevelDataAdded, Field: "overrides", Key: "my_mod_key", New: true})
+	assert.Contains(t, changes, LevelDataChange{Kind: LevelDataRemoved, Field: "overrides", Key: "wormhole_prefab", Old: "wormhole"})
+	assert.Contains(t, changes, LevelDataChange{Kind: LevelDataRemoved, Field: "random_set_pieces", Old: "Sculptures_2"})
+	assert.Contains(t, changes, LevelDataChange{Kind: LevelDataAdded, Field: "random_set_pieces", New: "Maxwell8"})
+
+	for _, change := range changes {
+		t.Log(change)
+	}
+}
+
+func TestLevelDataOverridesDiffExtra(t *testing.T) {
+	from := LevelDataOverrides{Extra: map[string]any{"weights": []any{1.0}, "valid_start_tasks": []any{"Make a pick"}}}
+	to := LevelDataOverrides{Extra: map[string]any{"weights": []any{2.0}, "ordered_story_setpieces": []any{"TeleportatoRingLayout"}}}
+
+	assert.Equal(t, []LevelDataChange{
+		{Kind: LevelDataAdded, Field: "ordered_story_setpieces", New: []any{"TeleportatoRingLayout"}},
+		{Kind: LevelDataRemoved, Field: "valid_start_tasks", Old: []any{"Make a pick"}},
+		{Kind: LevelDataModified, Field: "weights", Old: []any{1.0}, New: []any{2.0}},
+	}, from.Diff(to))
+}
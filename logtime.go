@@ -0,0 +1,102 @@
+package dstparser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseLogOffset parses the time like 00:01:18 in log line, which is the elapsed time since server started,
+// hours may be greater than 24 for long-running server.
+func ParseLogOffset(s string) (time.Duration, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	fields := strings.Split(s, ":")
+	if len(fields) != 3 {
+		return 0, fmt.Errorf("invalid log time: %q", s)
+	}
+	var values [3]int
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil || value < 0 || (i > 0 && value >= 60) {
+			return 0, fmt.Errorf("invalid log time: %q", s)
+		}
+		values[i] = value
+	}
+	return time.Duration(values[0])*time.Hour + time.Duration(values[1])*time.Minute + time.Duration(values[2])*time.Second, nil
+}
+
+// FormatLogOffset formats offset as the time in log line, egs. 00:01:18
+func FormatLogOffset(offset time.Duration) string {
+	seconds := int64(offset / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// LogClock converts the elapsed offsets of consecutive log lines into absolute time.
+// If the offset goes backwards, it is considered as a rollover, the server restarted right after the previous line,
+// and the following offsets are counted from there.
+type LogClock struct {
+	start time.Time
+	base  time.Duration
+	last  time.Duration
+}
+
+// NewLogClock returns a LogClock with the session start time
+func NewLogClock(start time.Time) *LogClock {
+	return &LogClock{start: start}
+}
+
+// Time returns the absolute time of the offset of next line
+func (c *LogClock) Time(offset time.Duration) time.Time {
+	if offset < c.last {
+		c.base += c.last
+	}
+	c.last = offset
+	return c.start.Add(c.base + offset)
+}
+
+// StampChatLogs fills the Timestamp of consecutive chat logs from the session start time
+func StampChatLogs(logs []ChatLog, start time.Time) {
+	clock := NewLogClock(start)
+	for i := range logs {
+		logs[i].Timestamp = clock.Time(logs[i].Offset)
+	}
+}
+
+// StartFromModTime estimates the session start time from the modification time of log file,
+// and the offset of the last line in it.
+func StartFromModTime(modTime time.Time, lastOffset time.Duration) time.Time {
+	return modTime.Add(-lastOffset)
+}
+
+// serverLogTimeLayout is the layout of "Current time" line in server_log.txt
+const serverLogTimeLayout = "Mon Jan _2 15:04:05 2006"
+
+// ParseServerLogStartTime finds the "Current time: ..." line written at start up in server_log.txt,
+// returns the start time of the session in loc.
+func ParseServerLogStartTime(content []byte, loc *time.Location) (time.Time, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		_, value, found := strings.Cut(scanner.Text(), "]: Current time: ")
+		if !found {
+			continue
+		}
+		current, err := time.ParseInLocation(serverLogTimeLayout, strings.TrimSpace(value), loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+		// the line is written after the server started for a while
+		offset, err := ParseLogOffset(scanner.Text()[:strings.Index(scanner.Text(), "]")+1])
+		if err != nil {
+			return time.Time{}, err
+		}
+		return current.Add(-offset), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, errors.New("start time not found in server log")
+}
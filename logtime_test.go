@@ -0,0 +1,55 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseLogOffset(t *testing.T) {
+	offset, err := ParseLogOffset("00:01:18")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 78*time.Second, offset)
+
+	offset, err = ParseLogOffset("[123:00:05]")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 123*time.Hour+5*time.Second, offset)
+	assert.EqualValues(t, "123:00:05", FormatLogOffset(offset))
+
+	_, err = ParseLogOffset("00:61:00")
+	assert.NotNil(t, err)
+}
+
+func TestStampChatLogs(t *testing.T) {
+	const chatlog = `[23:59:58]: [Say] (KU_iJIpcpXi) a: 1
+[24:00:01]: [Say] (KU_iJIpcpXi) a: 2
+[00:00:05]: [Say] (KU_iJIpcpXi) a: 3`
+
+	logs, err := ParseServerChatLogs([]byte(chatlog))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 24*time.Hour+time.Second, logs[1].Offset)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	StampChatLogs(logs, start)
+	assert.EqualValues(t, time.Date(2024, 1, 1, 23, 59, 58, 0, time.UTC), logs[0].Timestamp)
+	assert.EqualValues(t, time.Date(2024, 1, 2, 0, 0, 1, 0, time.UTC), logs[1].Timestamp)
+	// restarted after the previous line
+	assert.EqualValues(t, time.Date(2024, 1, 2, 0, 0, 6, 0, time.UTC), logs[2].Timestamp)
+
+	modTime := time.Date(2024, 1, 2, 0, 0, 1, 0, time.UTC)
+	assert.EqualValues(t, start, StartFromModTime(modTime, logs[1].Offset))
+}
+
+func TestParseServerLogStartTime(t *testing.T) {
+	const serverlog = `[00:00:00]: PersistRootStorage is now APP:Klei//DoNotStarveTogether/Cluster_1/Master/
+[00:00:00]: Starting Up
+[00:00:00]: Version: 592193
+[00:00:01]: Current time: Sat Apr  6 12:30:00 2024`
+
+	start, err := ParseServerLogStartTime([]byte(serverlog), time.UTC)
+	assert.Nil(t, err)
+	assert.EqualValues(t, time.Date(2024, 4, 6, 12, 29, 59, 0, time.UTC), start)
+
+	_, err = ParseServerLogStartTime([]byte("[00:00:00]: Starting Up"), time.UTC)
+	assert.NotNil(t, err)
+}
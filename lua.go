@@ -71,3 +71,47 @@ func judgeOptionValue(value lua.LValue) any {
 		return lua.LVAsBool(value)
 	}
 }
+
+// luaToGo converts lua value to go value with full fidelity, tables with keys 1..n are converted to []any,
+// tables with only string keys are converted to map[string]any, other tables are converted to map[any]any
+// keyed by string, float64 or bool.
+func luaToGo(value lua.LValue) any {
+	switch value.Type() {
+	case lua.LTNil:
+		return nil
+	case lua.LTString:
+		return lua.LVAsString(value)
+	case lua.LTNumber:
+		return float64(lua.LVAsNumber(value))
+	case lua.LTBool:
+		return lua.LVAsBool(value)
+	case lua.LTTable:
+		table := value.(*lua.LTable)
+		n := table.Len()
+		count, stringKeys := 0, true
+		table.ForEach(func(key lua.LValue, _ lua.LValue) {
+			count++
+			stringKeys = stringKeys && key.Type() == lua.LTString
+		})
+		if count == n {
+			array := make([]any, 0, n)
+			for i := 1; i <= n; i++ {
+				array = append(array, luaToGo(table.RawGetInt(i)))
+			}
+			return array
+		}
+		if stringKeys {
+			dict := make(map[string]any, count)
+			table.ForEach(func(key lua.LValue, value lua.LValue) {
+				dict[key.String()] = luaToGo(value)
+			})
+			return dict
+		}
+		dict := make(map[any]any, count)
+		table.ForEach(func(key lua.LValue, value lua.LValue) {
+			dict[luaToGo(key)] = luaToGo(value)
+		})
+		return dict
+	}
+	return value.String()
+}
@@ -0,0 +1,379 @@
+package dstparser
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"gopkg.in/ini.v1"
+	"io/fs"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+)
+
+const (
+	// EnvRefPrefix marks a value that should be read from environment variable, egs. cluster_key = env:DST_CLUSTER_KEY
+	EnvRefPrefix = "env:"
+	// FileRefPrefix marks a value that should be read from file, egs. cluster_password = file:/run/secrets/password
+	FileRefPrefix = "file:"
+
+	// key of cluster_token.txt in overlay
+	clusterTokenKey = "cluster_token"
+)
+
+// EnvOverlay applies environment variables and secret references on top of cluster.ini or server.ini.
+//
+// Values written as env:NAME or file:PATH are resolved, then each key can be overridden by
+// environment variable named Prefix + upper case key, egs. DST_CLUSTER_NAME overrides cluster_name,
+// DST_MASTER_SERVER_PORT overrides master_server_port.
+type EnvOverlay struct {
+	// prefix of override environment variables, no overriding if empty
+	Prefix string
+	// LookupEnv looks up environment variable, default is os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+	// ReadFile reads secret file, default is os.ReadFile
+	ReadFile func(name string) ([]byte, error)
+}
+
+// NewEnvOverlay returns an EnvOverlay reading from process environment and filesystem
+func NewEnvOverlay(prefix string) EnvOverlay {
+	return EnvOverlay{
+		Prefix:    prefix,
+		LookupEnv: os.LookupEnv,
+		ReadFile:  os.ReadFile,
+	}
+}
+
+// OverlayRef records the original value of an ini key replaced by overlay
+type OverlayRef struct {
+	// original value in ini file
+	Raw string
+	// whether the key exists in ini file
+	Present bool
+	// random salt of Digest
+	Salt []byte
+	// salted sha256 of the value after overlay applied, the value itself is not kept since it is usually a secret
+	Digest []byte
+}
+
+// newOverlayRef returns an OverlayRef recording the digest of value
+func newOverlayRef(raw string, present bool, value string) (OverlayRef, error) {
+	ref := OverlayRef{Raw: raw, Present: present, Salt: make([]byte, 16)}
+	if _, err := rand.Read(ref.Salt); err != nil {
+		return OverlayRef{}, err
+	}
+	ref.Digest = ref.sum(value)
+	return ref, nil
+}
+
+func (r OverlayRef) sum(value string) []byte {
+	mac := hmac.New(sha256.New, r.Salt)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// Matches reports whether value is the same as the value after overlay applied
+func (r OverlayRef) Matches(value string) bool {
+	return hmac.Equal(r.sum(value), r.Digest)
+}
+
+// OverlayRefs holds the overlay records of an ini file, keyed by SECTION.key, or cluster_token for cluster_token.txt,
+// it is used to restore the original references when writing back.
+type OverlayRefs map[string]OverlayRef
+
+// ParseClusterInI parses cluster.ini with overlay applied
+func (o EnvOverlay) ParseClusterInI(data []byte) (ClusterConfig, OverlayRefs, error) {
+	var config ClusterConfig
+	refs, err := o.mapTo(&config, data)
+	if err != nil {
+		return ClusterConfig{}, nil, err
+	}
+	return config, refs, nil
+}
+
+// ParseServerInI parses server.ini with overlay applied
+func (o EnvOverlay) ParseServerInI(data []byte) (ServerConfig, OverlayRefs, error) {
+	var config ServerConfig
+	refs, err := o.mapTo(&config, data)
+	if err != nil {
+		return ServerConfig{}, nil, err
+	}
+	return config, refs, nil
+}
+
+func (o EnvOverlay) mapTo(config any, data []byte) (OverlayRefs, error) {
+	file, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(OverlayRefs)
+	for _, key := range iniKeysOf(reflect.TypeOf(config).Elem()) {
+		section := file.Section(key.Section)
+		raw, present := "", section.HasKey(key.Name)
+		if present {
+			raw = section.Key(key.Name).String()
+		}
+
+		value, err := o.resolve(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", key.Section, key.Name, err)
+		}
+		if env, ok := o.lookup(key.Name); ok {
+			value = env
+		}
+
+		if value != raw {
+			section.Key(key.Name).SetValue(value)
+			refs[key.String()] = OverlayRef{Raw: raw, Present: present}
+		}
+	}
+
+	if err := file.MapTo(config); err != nil {
+		return nil, err
+	}
+
+	// record the overlay value in the same form as it will be written back
+	reflected := ini.Empty()
+	if err := reflected.ReflectFrom(config); err != nil {
+		return nil, err
+	}
+	for name, ref := range refs {
+		section, key, _ := strings.Cut(name, ".")
+		if refs[name], err = newOverlayRef(ref.Raw, ref.Present, reflected.Section(section).Key(key).String()); err != nil {
+			return nil, err
+		}
+	}
+
+	return refs, nil
+}
+
+// lookup looks up the override environment variable of key, egs. DST_CLUSTER_NAME for cluster_name
+func (o EnvOverlay) lookup(key string) (string, bool) {
+	if len(o.Prefix) == 0 {
+		return "", false
+	}
+	return o.lookupEnv(o.Prefix + strings.ToUpper(key))
+}
+
+// lookupEnv looks up environment variable with LookupEnv, or os.LookupEnv if not set
+func (o EnvOverlay) lookupEnv(name string) (string, bool) {
+	if o.LookupEnv == nil {
+		return os.LookupEnv(name)
+	}
+	return o.LookupEnv(name)
+}
+
+// resolve resolves env: and file: references
+func (o EnvOverlay) resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, EnvRefPrefix):
+		name := strings.TrimPrefix(value, EnvRefPrefix)
+		env, ok := o.lookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s not set", name)
+		}
+		return env, nil
+	case strings.HasPrefix(value, FileRefPrefix):
+		readFile := o.ReadFile
+		if readFile == nil {
+			readFile = os.ReadFile
+		}
+		content, err := readFile(strings.TrimPrefix(value, FileRefPrefix))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(content)), nil
+	}
+	return value, nil
+}
+
+// ParseClusterToken parses cluster_token.txt with overlay applied, the content can be an env:NAME or file:PATH
+// reference, and environment variable Prefix + CLUSTER_TOKEN overrides it, egs. DST_CLUSTER_TOKEN.
+// If the file does not exist and no override is set, the token is empty without error.
+func (o EnvOverlay) ParseClusterToken(data []byte, exists bool) (ClusterToken, OverlayRefs, error) {
+	raw := ""
+	if exists {
+		raw = strings.TrimSpace(string(bytes.TrimPrefix(data, utf8BOM)))
+	}
+
+	value, err := o.resolve(raw)
+	if err != nil {
+		return ClusterToken{}, nil, fmt.Errorf("%s: %w", clusterTokenKey, err)
+	}
+	if env, ok := o.lookup(clusterTokenKey); ok {
+		value = env
+	}
+	if !exists && len(value) == 0 {
+		return ClusterToken{}, nil, nil
+	}
+
+	token, err := ParseClusterToken([]byte(value))
+	if err != nil {
+		return ClusterToken{}, nil, err
+	}
+	refs := make(OverlayRefs)
+	if value != raw {
+		if refs[clusterTokenKey], err = newOverlayRef(raw, exists, token.Format()); err != nil {
+			return ClusterToken{}, nil, err
+		}
+	}
+	return token, refs, nil
+}
+
+// ToClusterInI converts ClusterConfig to cluster.ini, restores the original references for unchanged values
+func (r OverlayRefs) ToClusterInI(config ClusterConfig) ([]byte, error) {
+	return r.toInI(&config)
+}
+
+// ToServerInI converts ServerConfig to server.ini, restores the original references for unchanged values
+func (r OverlayRefs) ToServerInI(config ServerConfig) ([]byte, error) {
+	return r.toInI(&config)
+}
+
+// ToClusterTokenTxt converts token to cluster_token.txt, restores the original reference for unchanged token.
+// Nil is returned for unchanged token if cluster_token.txt did not exist, the file should not be written.
+func (r OverlayRefs) ToClusterTokenTxt(token ClusterToken) ([]byte, error) {
+	if ref, ok := r[clusterTokenKey]; ok && ref.Matches(token.Format()) {
+		if !ref.Present {
+			return nil, nil
+		}
+		return []byte(ref.Raw), nil
+	}
+	return ToClusterTokenTxt(token)
+}
+
+func (r OverlayRefs) toInI(config any) ([]byte, error) {
+	empty := ini.Empty()
+	err := empty.ReflectFrom(config)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, ref := range r {
+		sectionName, keyName, _ := strings.Cut(name, ".")
+		section := empty.Section(sectionName)
+		// value has been changed since parsed, keep the new one
+		if !ref.Matches(section.Key(keyName).String()) {
+			continue
+		}
+		if ref.Present {
+			section.Key(keyName).SetValue(ref.Raw)
+		} else {
+			section.DeleteKey(keyName)
+		}
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	if _, err := empty.WriteToIndent(buffer, "\t"); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// iniKey represents a key in ini section
+type iniKey struct {
+	Section string
+	Name    string
+	// index path of the field in config struct
+	Index []int
+}
+
+func (k iniKey) String() string {
+	return k.Section + "." + k.Name
+}
+
+// iniKeysOf returns all ini keys declared by ini tags of config struct type
+func iniKeysOf(typ reflect.Type) []iniKey {
+	var keys []iniKey
+	for i := 0; i < typ.NumField(); i++ {
+		sectionField := typ.Field(i)
+		section, _, _ := strings.Cut(sectionField.Tag.Get("ini"), ",")
+		if len(section) == 0 || sectionField.Type.Kind() != reflect.Struct {
+			continue
+		}
+		for j := 0; j < sectionField.Type.NumField(); j++ {
+			field := sectionField.Type.Field(j)
+			name, _, _ := strings.Cut(field.Tag.Get("ini"), ",")
+			if len(name) == 0 || name == "-" {
+				continue
+			}
+			keys = append(keys, iniKey{Section: section, Name: name, Index: []int{i, j}})
+		}
+	}
+	return keys
+}
+
+// ClusterOverlayRefs holds the overlay records of a cluster folder loaded by EnvOverlay.LoadCluster
+type ClusterOverlayRefs struct {
+	Config OverlayRefs
+	Token  OverlayRefs
+	// keyed by shard name
+	Shards map[string]OverlayRefs
+}
+
+// LoadCluster loads cluster from the cluster folder like LoadCluster, with overlay applied to cluster.ini and
+// cluster_token.txt. Only env: and file: references are resolved in server.ini of shards, since environment
+// variables named by key can not tell shards apart. The file: references are read from fsys instead of ReadFile,
+// the path is relative to the cluster folder, egs. file:secrets/cluster_key.
+func (o EnvOverlay) LoadCluster(fsys fs.FS) (Cluster, ClusterOverlayRefs, error) {
+	o.ReadFile = func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	}
+	refs := ClusterOverlayRefs{Shards: make(map[string]OverlayRefs)}
+	shardOverlay := o
+	shardOverlay.Prefix = ""
+
+	cluster, err := loadCluster(fsys, clusterParsers{
+		clusterIni: func(data []byte) (config ClusterConfig, err error) {
+			config, refs.Config, err = o.ParseClusterInI(data)
+			return config, err
+		},
+		token: func(data []byte, exists bool) (token ClusterToken, err error) {
+			token, refs.Token, err = o.ParseClusterToken(data, exists)
+			return token, err
+		},
+		serverIni: func(shard string, data []byte) (config ServerConfig, err error) {
+			config, refs.Shards[shard], err = shardOverlay.ParseServerInI(data)
+			return config, err
+		},
+	})
+	if err != nil {
+		return Cluster{}, ClusterOverlayRefs{}, err
+	}
+	return cluster, refs, nil
+}
+
+// Files returns the content of all files in the cluster folder like Cluster.Files, restores the original
+// references for unchanged values.
+func (r ClusterOverlayRefs) Files(c Cluster) (map[string][]byte, error) {
+	files, err := c.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	if files["cluster.ini"], err = r.Config.ToClusterInI(c.Config); err != nil {
+		return nil, err
+	}
+
+	delete(files, "cluster_token.txt")
+	if !c.Token.IsZero() {
+		token, err := r.Token.ToClusterTokenTxt(c.Token)
+		if err != nil {
+			return nil, err
+		}
+		if token != nil {
+			files["cluster_token.txt"] = token
+		}
+	}
+
+	for _, shard := range c.Shards {
+		if files[path.Join(shard.Name, "server.ini")], err = r.Shards[shard.Name].ToServerInI(shard.Server); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,214 @@
+package dstparser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testEnvOverlay(env map[string]string, files map[string]string) EnvOverlay {
+	return EnvOverlay{
+		Prefix: "DST_",
+		LookupEnv: func(key string) (string, bool) {
+			val, ok := env[key]
+			return val, ok
+		},
+		ReadFile: func(name string) ([]byte, error) {
+			content, ok := files[name]
+			if !ok {
+				return nil, errors.New("file not found")
+			}
+			return []byte(content), nil
+		},
+	}
+}
+
+func TestEnvOverlayParseClusterInI(t *testing.T) {
+	const clusterIni = `[NETWORK]
+cluster_name = local
+cluster_password = env:CLUSTER_PASSWORD
+
+[SHARD]
+cluster_key = file:/run/secrets/cluster_key
+master_port = 10888
+`
+	overlay := testEnvOverlay(map[string]string{
+		"CLUSTER_PASSWORD": "secret",
+		"DST_CLUSTER_NAME": "container",
+		"DST_MASTER_PORT":  "10900",
+	}, map[string]string{
+		"/run/secrets/cluster_key": "supersecretkey\n",
+	})
+
+	config, refs, err := overlay.ParseClusterInI([]byte(clusterIni))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "container", config.NetWork.ClusterName)
+	assert.EqualValues(t, "secret", config.NetWork.ClusterPassword)
+	assert.EqualValues(t, "supersecretkey", config.Shard.ClusterKey)
+	assert.EqualValues(t, 10900, config.Shard.MasterPort)
+
+	clusterInIData, err := refs.ToClusterInI(config)
+	assert.Nil(t, err)
+
+	content := string(clusterInIData)
+	assert.True(t, strings.Contains(content, "env:CLUSTER_PASSWORD"))
+	assert.True(t, strings.Contains(content, "file:/run/secrets/cluster_key"))
+	assert.True(t, strings.Contains(content, "10888"))
+	assert.False(t, strings.Contains(content, "supersecretkey"))
+}
+
+func TestEnvOverlayChanged(t *testing.T) {
+	overlay := testEnvOverlay(map[string]string{"DST_SERVER_PORT": "11005"}, nil)
+	config, refs, err := overlay.ParseServerInI([]byte("[NETWORK]\nserver_port = 11000\n"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 11005, config.Network.ServerPort)
+
+	// changed value will not be restored
+	config.Network.ServerPort = 11010
+	serverIni, err := refs.ToServerInI(config)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(serverIni), "11010"))
+}
+
+func TestEnvOverlayMissingEnv(t *testing.T) {
+	overlay := testEnvOverlay(nil, nil)
+	_, _, err := overlay.ParseClusterInI([]byte("[SHARD]\ncluster_key = env:MISSING\n"))
+	assert.NotNil(t, err)
+}
+
+func TestEnvOverlayRefsSecret(t *testing.T) {
+	overlay := testEnvOverlay(map[string]string{"CLUSTER_PASSWORD": "secret"}, nil)
+	config, refs, err := overlay.ParseClusterInI([]byte("[NETWORK]\ncluster_password = env:CLUSTER_PASSWORD\n"))
+	assert.Nil(t, err)
+
+	// refs keep only the digest of overlay value
+	refsData, err := json.Marshal(refs)
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(fmt.Sprintf("%+v", refs), "secret"))
+	assert.False(t, strings.Contains(string(refsData), "secret"))
+	assert.True(t, refs["NETWORK.cluster_password"].Matches("secret"))
+	assert.False(t, refs["NETWORK.cluster_password"].Matches("other"))
+
+	clusterIni, err := refs.ToClusterInI(config)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(clusterIni), "env:CLUSTER_PASSWORD"))
+
+	config.NetWork.ClusterPassword = "changed"
+	clusterIni, err = refs.ToClusterInI(config)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(clusterIni), "changed"))
+}
+
+func TestEnvOverlayParseClusterToken(t *testing.T) {
+	overlay := testEnvOverlay(map[string]string{"CLUSTER_TOKEN": testClusterToken}, map[string]string{
+		"/run/secrets/cluster_token": testClusterToken + "\n",
+	})
+
+	for _, ref := range []string{"env:CLUSTER_TOKEN", "file:/run/secrets/cluster_token"} {
+		token, refs, err := overlay.ParseClusterToken([]byte(ref+"\n"), true)
+		assert.Nil(t, err, ref)
+		assert.EqualValues(t, "KU_iJIpcpXi", token.KleiId, ref)
+
+		tokenTxt, err := refs.ToClusterTokenTxt(token)
+		assert.Nil(t, err, ref)
+		assert.EqualValues(t, ref, string(tokenTxt))
+	}
+
+	// plain token has nothing to restore
+	token, refs, err := overlay.ParseClusterToken([]byte(testClusterToken), true)
+	assert.Nil(t, err)
+	assert.Empty(t, refs)
+	tokenTxt, err := refs.ToClusterTokenTxt(token)
+	assert.Nil(t, err)
+	assert.EqualValues(t, testClusterToken, string(tokenTxt))
+
+	// no file and no override
+	token, refs, err = overlay.ParseClusterToken(nil, false)
+	assert.Nil(t, err)
+	assert.True(t, token.IsZero())
+
+	_, _, err = overlay.ParseClusterToken([]byte("env:MISSING"), true)
+	assert.NotNil(t, err)
+}
+
+func TestEnvOverlayClusterTokenEnv(t *testing.T) {
+	overlay := testEnvOverlay(map[string]string{"DST_CLUSTER_TOKEN": testClusterToken}, nil)
+
+	// cluster_token.txt does not exist
+	token, refs, err := overlay.ParseClusterToken(nil, false)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "KU_iJIpcpXi", token.KleiId)
+	tokenTxt, err := refs.ToClusterTokenTxt(token)
+	assert.Nil(t, err)
+	assert.Nil(t, tokenTxt)
+
+	// overrides the token in file
+	token, refs, err = overlay.ParseClusterToken([]byte("pds-g^KU_other^secret="), true)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "KU_iJIpcpXi", token.KleiId)
+	tokenTxt, err = refs.ToClusterTokenTxt(token)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "pds-g^KU_other^secret=", string(tokenTxt))
+
+	// changed token is written
+	token.Secret = "changed="
+	tokenTxt, err = refs.ToClusterTokenTxt(token)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(string(tokenTxt), "changed="))
+}
+
+func TestEnvOverlayLoadCluster(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cluster.ini":       {Data: []byte("[NETWORK]\ncluster_password = env:CLUSTER_PASSWORD\n")},
+		"cluster_token.txt": {Data: []byte("file:secrets/cluster_token\n")},
+		// file: references are read from cluster folder
+		"secrets/cluster_token": {Data: []byte(testClusterToken)},
+		"Master/server.ini":     {Data: []byte("[NETWORK]\nserver_port = env:MASTER_PORT\n")},
+		"Caves/server.ini":      {Data: []byte("[NETWORK]\nserver_port = 11001\n")},
+	}
+	overlay := testEnvOverlay(map[string]string{
+		"CLUSTER_PASSWORD": "secret",
+		"MASTER_PORT":      "11000",
+		"DST_CLUSTER_NAME": "container",
+		// prefix is not applied to server.ini of shards
+		"DST_SERVER_PORT": "12000",
+	}, nil)
+
+	cluster, refs, err := overlay.LoadCluster(fsys)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "secret", cluster.Config.NetWork.ClusterPassword)
+	assert.EqualValues(t, "container", cluster.Config.NetWork.ClusterName)
+	assert.EqualValues(t, "KU_iJIpcpXi", cluster.Token.KleiId)
+	assert.Len(t, cluster.Shards, 2)
+	for _, shard := range cluster.Shards {
+		if shard.Name == "Master" {
+			assert.EqualValues(t, 11000, shard.Server.Network.ServerPort)
+		} else {
+			assert.EqualValues(t, 11001, shard.Server.Network.ServerPort)
+		}
+	}
+
+	files, err := refs.Files(cluster)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(files["cluster.ini"]), "env:CLUSTER_PASSWORD"))
+	assert.False(t, strings.Contains(string(files["cluster.ini"]), "container"))
+	assert.EqualValues(t, "file:secrets/cluster_token", string(files["cluster_token.txt"]))
+	assert.True(t, strings.Contains(string(files["Master/server.ini"]), "env:MASTER_PORT"))
+	assert.True(t, strings.Contains(string(files["Caves/server.ini"]), "11001"))
+}
+
+func TestEnvOverlayProcessEnv(t *testing.T) {
+	t.Setenv("DSTPARSER_TEST_PASSWORD", "secret")
+	t.Setenv("DSTPARSER_TEST_CLUSTER_NAME", "container")
+
+	// environment variables of process are used for both references and overriding if LookupEnv is not set
+	overlay := EnvOverlay{Prefix: "DSTPARSER_TEST_"}
+	config, _, err := overlay.ParseClusterInI([]byte("[NETWORK]\ncluster_name = local\ncluster_password = env:DSTPARSER_TEST_PASSWORD\n"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "secret", config.NetWork.ClusterPassword)
+	assert.EqualValues(t, "container", config.NetWork.ClusterName)
+}
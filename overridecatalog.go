@@ -0,0 +1,377 @@
+package dstparser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OverrideCategory is the category of world override, worldgen overrides take effect when the world is generated,
+// settings overrides can be changed for an existing world.
+type OverrideCategory string
+
+const (
+	OverrideWorldGen OverrideCategory = "worldgen"
+	OverrideSettings OverrideCategory = "settings"
+)
+
+// OverrideKey describes a key in overrides of leveldataoverride.lua
+type OverrideKey struct {
+	Name     string
+	Category OverrideCategory
+	// group in customization screen, egs. resources, monsters, giants
+	Group string
+	// allowed values in the order of customization screen, strings or bools
+	Values []any
+	// default value, see DefaultFor for location specific default
+	Default any
+	// default values differ from Default, keyed by location
+	LocationDefaults map[string]any
+	// locations which the key is available in
+	Locations []string
+	// hidden keys are written by presets but not shown in customization screen, egs. layout_mode
+	Hidden bool
+}
+
+// DefaultFor returns the default value of key in location
+func (k OverrideKey) DefaultFor(location string) any {
+	if value, ok := k.LocationDefaults[location]; ok {
+		return value
+	}
+	return k.Default
+}
+
+// Allows reports whether value is an allowed value of key
+func (k OverrideKey) Allows(value any) bool {
+	for _, v := range k.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailableIn reports whether key is available in location
+func (k OverrideKey) AvailableIn(location string) bool {
+	for _, l := range k.Locations {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	forestOnly    = []string{LocationForest}
+	caveOnly      = []string{LocationCave}
+	forestAndCave = []string{LocationForest, LocationCave}
+	allWorlds     = []string{LocationForest, LocationCave, LocationLavaArena, LocationQuagmire}
+	eventWorld    = []string{LocationLavaArena, LocationQuagmire}
+
+	worldgenAmounts = []any{"never", "rare", "uncommon", "default", "often", "mostly", "always", "insane"}
+	oceanAmounts    = []any{"ocean_never", "ocean_rare", "ocean_uncommon", "ocean_default", "ocean_often", "ocean_mostly", "ocean_always", "ocean_insane"}
+	frequencies     = []any{"never", "rare", "default", "often", "always"}
+	speeds          = []any{"never", "veryslow", "slow", "default", "fast", "veryfast"}
+	rates           = []any{"veryslow", "slow", "default", "fast", "veryfast"}
+	seasonLengths   = []any{"noseason", "veryshortseason", "shortseason", "default", "longseason", "verylongseason", "random"}
+	enabledEvents   = []any{"default", "enabled"}
+	neverDefault    = []any{"never", "default"}
+	noneAlways      = []any{"none", "always"}
+	nonLethal       = []any{"nonlethal", "default"}
+	toggles         = []any{true, false}
+)
+
+// overrideKeys returns keys sharing the same attributes, with "default" as default value
+func overrideKeys(category OverrideCategory, group string, values []any, locations []string, names ...string) []OverrideKey {
+	keys := make([]OverrideKey, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, OverrideKey{
+			Name:      name,
+			Category:  category,
+			Group:     group,
+			Values:    values,
+			Default:   "default",
+			Locations: locations,
+		})
+	}
+	return keys
+}
+
+func concatOverrideKeys(groups ...[]OverrideKey) []OverrideKey {
+	var keys []OverrideKey
+	for _, group := range groups {
+		keys = append(keys, group...)
+	}
+	return keys
+}
+
+// overrideCatalog is all known override keys in the order of customization screen
+var overrideCatalog = concatOverrideKeys(
+	// worldgen
+	[]OverrideKey{
+		{Name: "task_set", Category: OverrideWorldGen, Group: "misc",
+			Values:           []any{"default", "classic", "cave_default", "lavaarena_taskset", "quagmire_taskset"},
+			Default:          "default",
+			LocationDefaults: map[string]any{LocationCave: "cave_default", LocationLavaArena: "lavaarena_taskset", LocationQuagmire: "quagmire_taskset"},
+			Locations:        allWorlds},
+		{Name: "start_location", Category: OverrideWorldGen, Group: "misc",
+			Values:           []any{"default", "plus", "darkness", "caves", "lavaarena", "quagmire_startlocation"},
+			Default:          "default",
+			LocationDefaults: map[string]any{LocationCave: "caves", LocationLavaArena: "lavaarena", LocationQuagmire: "quagmire_startlocation"},
+			Locations:        allWorlds},
+		{Name: "world_size", Category: OverrideWorldGen, Group: "misc",
+			Values: []any{"small", "medium", "default", "huge"}, Default: "default",
+			LocationDefaults: map[string]any{LocationLavaArena: "small", LocationQuagmire: "small"},
+			Locations:        allWorlds},
+		{Name: "branching", Category: OverrideWorldGen, Group: "misc",
+			Values: []any{"never", "least", "default", "most", "random"}, Default: "default", Locations: forestAndCave},
+		{Name: "loop", Category: OverrideWorldGen, Group: "misc",
+			Values: []any{"never", "default", "always"}, Default: "default", Locations: forestAndCave},
+		{Name: "roads", Category: OverrideWorldGen, Group: "misc",
+			Values: neverDefault, Default: "default",
+			LocationDefaults: map[string]any{LocationCave: "never", LocationLavaArena: "never", LocationQuagmire: "never"},
+			Locations:        allWorlds},
+		{Name: "season_start", Category: OverrideWorldGen, Group: "misc",
+			Values: []any{"default", "winter", "spring", "summer", "autumnorspring", "winterorsummer", "random"}, Default: "default",
+			Locations: allWorlds},
+		{Name: "prefabswaps_start", Category: OverrideWorldGen, Group: "misc",
+			Values: []any{"classic", "default", "highlyrandom"}, Default: "default", Locations: forestAndCave},
+		{Name: "touchstone", Category: OverrideWorldGen, Group: "misc",
+			Values: worldgenAmounts, Default: "default",
+			LocationDefaults: map[string]any{LocationLavaArena: "never", LocationQuagmire: "never"},
+			Locations:        allWorlds},
+		{Name: "boons", Category: OverrideWorldGen, Group: "misc",
+			Values: worldgenAmounts, Default: "default",
+			LocationDefaults: map[string]any{LocationLavaArena: "never", LocationQuagmire: "never"},
+			Locations:        allWorlds},
+		{Name: "terrariumchest", Category: OverrideWorldGen, Group: "misc",
+			Values: neverDefault, Default: "default", Locations: forestOnly},
+		{Name: "layout_mode", Category: OverrideWorldGen, Group: "misc",
+			Values: []any{"LinkNodesByKeys", "RestrictNodesByKey"}, Default: "LinkNodesByKeys",
+			LocationDefaults: map[string]any{LocationCave: "RestrictNodesByKey", LocationLavaArena: "RestrictNodesByKey", LocationQuagmire: "RestrictNodesByKey"},
+			Locations:        allWorlds, Hidden: true},
+		{Name: "wormhole_prefab", Category: OverrideWorldGen, Group: "misc",
+			Values: []any{"wormhole", "tentacle_pillar"}, Default: "wormhole",
+			LocationDefaults: map[string]any{LocationCave: "tentacle_pillar"},
+			Locations:        forestAndCave, Hidden: true},
+		{Name: "has_ocean", Category: OverrideWorldGen, Group: "misc",
+			Values: toggles, Default: true, Locations: forestOnly, Hidden: true},
+		{Name: "keep_disconnected_tiles", Category: OverrideWorldGen, Group: "misc",
+			Values: toggles, Default: true, Locations: []string{LocationForest, LocationLavaArena, LocationQuagmire}, Hidden: true},
+		{Name: "no_joining_islands", Category: OverrideWorldGen, Group: "misc",
+			Values: toggles, Default: true, Locations: forestOnly, Hidden: true},
+		{Name: "no_wormholes_to_disconnected_tiles", Category: OverrideWorldGen, Group: "misc",
+			Values: toggles, Default: true, Locations: forestOnly, Hidden: true},
+		{Name: "traps", Category: OverrideWorldGen, Group: "misc",
+			Values: neverDefault, Default: "never", Locations: eventWorld, Hidden: true},
+		{Name: "poi", Category: OverrideWorldGen, Group: "misc",
+			Values: neverDefault, Default: "never", Locations: eventWorld, Hidden: true},
+		{Name: "protected", Category: OverrideWorldGen, Group: "misc",
+			Values: neverDefault, Default: "never", Locations: eventWorld, Hidden: true},
+	},
+	overrideKeys(OverrideWorldGen, "misc", worldgenAmounts, forestOnly, "moon_fissure"),
+	overrideKeys(OverrideWorldGen, "resources", worldgenAmounts, forestAndCave,
+		"grass", "sapling", "marshbush", "reeds", "trees", "flint", "rock", "mushroom", "berrybush"),
+	overrideKeys(OverrideWorldGen, "resources", worldgenAmounts, forestOnly,
+		"flowers", "tumbleweed", "rock_ice", "meteorspawner", "cactus", "carrot", "ponds", "palmconetree",
+		"moon_tree", "moon_sapling", "moon_berrybush", "moon_carrot", "moon_rock", "moon_hotspring", "moon_starfish",
+		"moon_bullkelp", "ocean_bullkelp", "ocean_shoal", "ocean_wobsterden"),
+	[]OverrideKey{
+		{Name: "ocean_seastack", Category: OverrideWorldGen, Group: "resources",
+			Values: oceanAmounts, Default: "ocean_default", Locations: forestOnly},
+		{Name: "ocean_waterplant", Category: OverrideWorldGen, Group: "resources",
+			Values: oceanAmounts, Default: "ocean_default", Locations: forestOnly},
+	},
+	overrideKeys(OverrideWorldGen, "resources", worldgenAmounts, caveOnly,
+		"banana", "cave_ponds", "fern", "fissure", "flower_cave", "lichen", "mushtree", "wormlights"),
+	overrideKeys(OverrideWorldGen, "animals", worldgenAmounts, forestOnly,
+		"rabbits", "moles", "beefalo", "lightninggoat", "bees", "catcoon", "buzzard", "pigs", "moon_fruitdragon"),
+	overrideKeys(OverrideWorldGen, "animals", worldgenAmounts, caveOnly, "bunnymen", "monkey", "rocky", "slurtles"),
+	overrideKeys(OverrideWorldGen, "monsters", worldgenAmounts, forestAndCave, "spiders", "chess", "tentacles"),
+	overrideKeys(OverrideWorldGen, "monsters", worldgenAmounts, forestOnly,
+		"houndmound", "merm", "angrybees", "tallbirds", "walrus", "moon_spider"),
+	overrideKeys(OverrideWorldGen, "monsters", worldgenAmounts, caveOnly, "bats", "slurper", "worms", "cave_spiders"),
+
+	// settings
+	[]OverrideKey{
+		{Name: "specialevent", Category: OverrideSettings, Group: "global",
+			Values: []any{"none", "default", "hallowed_nights", "winters_feast", "year_of_the_gobbler", "year_of_the_varg",
+				"year_of_the_pig", "year_of_the_carrat", "year_of_the_beefalo", "year_of_the_catcoon",
+				"year_of_the_bunnyman", "year_of_the_dragonfly", "crow_carnival"},
+			Default: "default", Locations: forestAndCave},
+	},
+	overrideKeys(OverrideSettings, "global", seasonLengths, forestOnly, "autumn", "winter", "spring", "summer"),
+	[]OverrideKey{
+		{Name: "day", Category: OverrideSettings, Group: "global",
+			Values:  []any{"default", "longday", "longdusk", "longnight", "noday", "nodusk", "nonight", "onlyday", "onlydusk", "onlynight"},
+			Default: "default", Locations: forestAndCave},
+	},
+	overrideKeys(OverrideSettings, "global", frequencies, forestAndCave, "beefaloheat", "krampus"),
+	overrideKeys(OverrideSettings, "events", enabledEvents, forestAndCave,
+		"crow_carnival", "hallowed_nights", "winters_feast", "year_of_the_gobbler", "year_of_the_varg", "year_of_the_pig",
+		"year_of_the_carrat", "year_of_the_beefalo", "year_of_the_catcoon", "year_of_the_bunnyman"),
+	[]OverrideKey{
+		{Name: "extrastartingitems", Category: OverrideSettings, Group: "survivors",
+			Values: []any{"0", "5", "default", "15", "20", "none"}, Default: "default", Locations: forestAndCave},
+		{Name: "seasonalstartingitems", Category: OverrideSettings, Group: "survivors",
+			Values: neverDefault, Default: "default", Locations: forestAndCave},
+		{Name: "spawnprotection", Category: OverrideSettings, Group: "survivors",
+			Values: []any{"never", "default", "always"}, Default: "default", Locations: forestAndCave},
+		{Name: "dropeverythingondespawn", Category: OverrideSettings, Group: "survivors",
+			Values: []any{"default", "always"}, Default: "default", Locations: forestAndCave},
+		{Name: "shadowcreatures", Category: OverrideSettings, Group: "survivors",
+			Values: frequencies, Default: "default", Locations: forestAndCave},
+		{Name: "brightmarecreatures", Category: OverrideSettings, Group: "survivors",
+			Values: frequencies, Default: "default", Locations: forestAndCave},
+		{Name: "ghostenabled", Category: OverrideSettings, Group: "survivors",
+			Values: noneAlways, Default: "always", Locations: forestAndCave},
+		{Name: "ghostsanitydrain", Category: OverrideSettings, Group: "survivors",
+			Values: noneAlways, Default: "always", Locations: forestAndCave},
+		{Name: "portalresurection", Category: OverrideSettings, Group: "survivors",
+			Values: noneAlways, Default: "none", Locations: forestAndCave},
+		{Name: "resettime", Category: OverrideSettings, Group: "survivors",
+			Values: []any{"none", "slow", "default", "fast", "always"}, Default: "default", Locations: forestAndCave},
+		{Name: "healthpenalty", Category: OverrideSettings, Group: "survivors",
+			Values: noneAlways, Default: "always", Locations: forestAndCave},
+		{Name: "lessdamagetaken", Category: OverrideSettings, Group: "survivors",
+			Values: noneAlways, Default: "none", Locations: forestAndCave},
+		{Name: "temperaturedamage", Category: OverrideSettings, Group: "survivors",
+			Values: nonLethal, Default: "default", Locations: forestAndCave},
+		{Name: "hunger", Category: OverrideSettings, Group: "survivors",
+			Values: nonLethal, Default: "default", Locations: forestAndCave},
+		{Name: "darkness", Category: OverrideSettings, Group: "survivors",
+			Values: nonLethal, Default: "default", Locations: forestAndCave},
+		{Name: "spawnmode", Category: OverrideSettings, Group: "survivors",
+			Values: []any{"fixed", "scatter"}, Default: "fixed", Locations: forestAndCave},
+		{Name: "regrowth", Category: OverrideSettings, Group: "world",
+			Values: rates, Default: "default", Locations: forestAndCave},
+		{Name: "petrification", Category: OverrideSettings, Group: "world",
+			Values: []any{"none", "few", "default", "many", "max"}, Default: "default", Locations: forestOnly},
+		{Name: "rifts_enabled", Category: OverrideSettings, Group: "world",
+			Values: []any{"never", "default", "always"}, Default: "default", Locations: forestOnly},
+		{Name: "rifts_enabled_cave", Category: OverrideSettings, Group: "world",
+			Values: []any{"never", "default", "always"}, Default: "default", Locations: caveOnly},
+		{Name: "stageplays", Category: OverrideSettings, Group: "world",
+			Values: neverDefault, Default: "default", Locations: forestOnly},
+		{Name: "atriumgate", Category: OverrideSettings, Group: "world",
+			Values: rates, Default: "default", Locations: caveOnly},
+		{Name: "cavelight", Category: OverrideSettings, Group: "world",
+			Values: rates, Default: "default", Locations: caveOnly},
+	},
+	overrideKeys(OverrideSettings, "world", frequencies, forestAndCave, "weather"),
+	overrideKeys(OverrideSettings, "world", frequencies, forestOnly,
+		"lightning", "frograin", "wildfires", "meteorshowers", "hunt", "alternatehunt", "rifts_frequency"),
+	overrideKeys(OverrideSettings, "world", frequencies, caveOnly, "earthquakes", "rifts_frequency_cave"),
+	[]OverrideKey{
+		{Name: "basicresource_regrowth", Category: OverrideSettings, Group: "resources",
+			Values: noneAlways, Default: "none", Locations: forestAndCave},
+	},
+	overrideKeys(OverrideSettings, "resources", speeds, forestOnly,
+		"carrots_regrowth", "flowers_regrowth", "reeds_regrowth", "evergreen_regrowth", "deciduoustree_regrowth",
+		"twiggytrees_regrowth", "saltstack_regrowth", "cactus_regrowth", "palmconetree_regrowth", "moon_tree_regrowth"),
+	overrideKeys(OverrideSettings, "resources", speeds, caveOnly,
+		"mushtree_regrowth", "mushtree_moon_regrowth", "flower_cave_regrowth", "lightflier_flower_regrowth"),
+	overrideKeys(OverrideSettings, "portal", speeds, forestOnly,
+		"portal_spawnrate", "bananabush_portalrate", "lightcrab_portalrate", "monkeytail_portalrate",
+		"palmcone_seed_portalrate", "powder_monkey_portalrate"),
+	overrideKeys(OverrideSettings, "animals", frequencies, forestAndCave,
+		"bunnymen_setting", "grassgekkos", "moles_setting", "pigs_setting"),
+	overrideKeys(OverrideSettings, "animals", frequencies, forestOnly,
+		"bees_setting", "birds", "butterfly", "catcoons", "fishschools", "penguins", "perd", "rabbits_setting", "wobsters"),
+	overrideKeys(OverrideSettings, "animals", frequencies, caveOnly,
+		"dustmoths", "lightfliers", "monkey_setting", "rocky_setting", "slurtles_setting", "snurtles"),
+	overrideKeys(OverrideSettings, "monsters", frequencies, forestAndCave,
+		"bats_setting", "liefs", "merms", "spider_warriors", "spiders_setting"),
+	overrideKeys(OverrideSettings, "monsters", frequencies, forestOnly,
+		"hounds", "hound_mounds", "lureplants", "moon_spiders", "mutated_hounds", "penguins_moon", "pirateraids",
+		"sharks", "squid", "wasps", "walrus_setting", "cookiecutters", "frogs", "gnarwail", "mosquitos", "deciduousmonster"),
+	overrideKeys(OverrideSettings, "monsters", neverDefault, forestOnly, "summerhounds", "winterhounds"),
+	overrideKeys(OverrideSettings, "monsters", frequencies, caveOnly,
+		"molebats", "mushgnome", "nightmarecreatures", "spider_dropper", "spider_hider", "spider_spitter", "wormattacks"),
+	overrideKeys(OverrideSettings, "giants", frequencies, forestAndCave, "fruitfly", "spiderqueen"),
+	overrideKeys(OverrideSettings, "giants", frequencies, forestOnly,
+		"antliontribute", "bearger", "beequeen", "crabking", "deerclops", "dragonfly", "eyeofterror", "goosemoose",
+		"klaus", "malbatross"),
+	overrideKeys(OverrideSettings, "giants", frequencies, caveOnly, "toadstool", "daywalker"),
+)
+
+var overrideCatalogIndex = func() map[string]int {
+	index := make(map[string]int, len(overrideCatalog))
+	for i, key := range overrideCatalog {
+		index[key.Name] = i
+	}
+	return index
+}()
+
+// LookupOverrideKey returns the catalog entry of override key
+func LookupOverrideKey(name string) (OverrideKey, bool) {
+	i, ok := overrideCatalogIndex[name]
+	if !ok {
+		return OverrideKey{}, false
+	}
+	return overrideCatalog[i], true
+}
+
+// OverrideKeys returns the catalog entries available in location in the order of customization screen,
+// returns all entries if location is empty.
+func OverrideKeys(location string) []OverrideKey {
+	var keys []OverrideKey
+	for _, key := range overrideCatalog {
+		if len(location) == 0 || key.AvailableIn(location) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+var (
+	ErrUnknownOverride      = errors.New("unknown override key")
+	ErrIllegalOverrideValue = errors.New("illegal override value")
+	ErrOverrideLocation     = errors.New("override key is not available in location")
+)
+
+// OverrideError reports an invalid item in overrides of leveldataoverride.lua
+type OverrideError struct {
+	Name  string
+	Value any
+	// one of ErrUnknownOverride, ErrIllegalOverrideValue, ErrOverrideLocation
+	Err error
+}
+
+func (e *OverrideError) Error() string {
+	return fmt.Sprintf("override %s=%v: %s", e.Name, e.Value, e.Err)
+}
+
+func (e *OverrideError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks overrides against the override key catalog, every unknown key, illegal value
+// and key not available in Location is reported as *OverrideError joined in the returned error.
+// Location check is skipped if Location is not a known one, egs. worlds of mods.
+func (l LevelDataOverrides) Validate() error {
+	knownLocation := isKnownLocation(l.Location)
+
+	var errs []error
+	for _, item := range l.Overrides {
+		key, ok := LookupOverrideKey(item.Name)
+		switch {
+		case !ok:
+			errs = append(errs, &OverrideError{Name: item.Name, Value: item.Value, Err: ErrUnknownOverride})
+		case !key.Allows(item.Value):
+			errs = append(errs, &OverrideError{Name: item.Name, Value: item.Value, Err: ErrIllegalOverrideValue})
+		case knownLocation && !key.AvailableIn(l.Location):
+			errs = append(errs, &OverrideError{Name: item.Name, Value: item.Value, Err: ErrOverrideLocation})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isKnownLocation reports whether location is one of the locations in override key catalog
+func isKnownLocation(location string) bool {
+	for _, l := range allWorlds {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package dstparser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestOverrideCatalog(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, key := range OverrideKeys("") {
+		assert.False(t, seen[key.Name], key.Name)
+		seen[key.Name] = true
+		assert.NotEmpty(t, key.Locations, key.Name)
+		for _, location := range key.Locations {
+			assert.True(t, key.Allows(key.DefaultFor(location)), key.Name)
+		}
+	}
+
+	key, ok := LookupOverrideKey("autumn")
+	assert.True(t, ok)
+	assert.Equal(t, OverrideSettings, key.Category)
+	assert.True(t, key.Allows("verylongseason"))
+	assert.False(t, key.Allows("often"))
+
+	key, ok = LookupOverrideKey("task_set")
+	assert.True(t, ok)
+	assert.Equal(t, "default", key.DefaultFor(LocationForest))
+	assert.Equal(t, "cave_default", key.DefaultFor(LocationCave))
+
+	_, ok = LookupOverrideKey("not_a_key")
+	assert.False(t, ok)
+}
+
+func TestOverrideKeys(t *testing.T) {
+	for _, key := range OverrideKeys(LocationCave) {
+		assert.NotEqual(t, "bearger", key.Name)
+	}
+	for _, key := range OverrideKeys(LocationForest) {
+		assert.NotEqual(t, "cave_ponds", key.Name)
+	}
+}
+
+func TestLevelDataOverridesValidate(t *testing.T) {
+	for _, file := range []string{"testdata/cluster/leveldataoverride.master.lua", "testdata/cluster/leveldataoverride.cave.lua"} {
+		bytes, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		overrides, err := ParseLevelDataOverrides(bytes)
+		assert.Nil(t, err)
+		assert.Nil(t, overrides.Validate(), file)
+	}
+
+	overrides := LevelDataOverrides{
+		Location: LocationCave,
+		Overrides: []LevelOverrideItem{
+			{Name: "cave_ponds", Value: "often"},
+			{Name: "autumn", Value: "often"},
+			{Name: "bearger", Value: "default"},
+			{Name: "my_mod_key", Value: "default"},
+		},
+	}
+	err := overrides.Validate()
+	assert.NotNil(t, err)
+	t.Log(err)
+	assert.True(t, errors.Is(err, ErrUnknownOverride))
+	assert.True(t, errors.Is(err, ErrIllegalOverrideValue))
+	assert.True(t, errors.Is(err, ErrOverrideLocation))
+
+	var overrideErr *OverrideError
+	assert.True(t, errors.As(err, &overrideErr))
+	assert.Equal(t, "autumn", overrideErr.Name)
+
+	// location check is skipped for worlds of mods
+	overrides.Location = "modded"
+	overrides.Overrides = overrides.Overrides[2:3]
+	assert.Nil(t, overrides.Validate())
+}
@@ -0,0 +1,809 @@
+package dstparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// locales of override labels, same as cluster_language in cluster.ini
+const (
+	LocaleEn = "en"
+	LocaleZh = "zh"
+)
+
+// overrideLabels is the display labels of override catalog in one locale
+type overrideLabels struct {
+	categories map[OverrideCategory]string
+	groups     map[string]string
+	keys       map[string]string
+	// labels of values shared by all keys
+	values map[string]string
+	// labels of values differ in category, egs. "rare" of worldgen
+	categoryValues map[OverrideCategory]map[string]string
+	// labels of values specific to key, keyed by key then value
+	keyValues map[string]map[string]string
+}
+
+// aliases of cluster_language which share labels of another locale
+var localeAliases = map[string]string{
+	"zhr": LocaleZh,
+}
+
+var (
+	toggleValuesEn = map[string]string{"none": "Disabled", "always": "Enabled"}
+	toggleValuesZh = map[string]string{"none": "禁用", "always": "启用"}
+	riftValuesEn   = map[string]string{"never": "Never", "default": "Auto", "always": "Always"}
+	riftValuesZh   = map[string]string{"never": "从不", "default": "自动", "always": "总是"}
+)
+
+var overrideLabelCatalog = map[string]overrideLabels{
+	LocaleEn: {
+		categories: map[OverrideCategory]string{
+			OverrideWorldGen: "World Generation",
+			OverrideSettings: "World Settings",
+		},
+		groups: map[string]string{
+			"misc":      "World",
+			"resources": "Resources",
+			"animals":   "Creatures",
+			"monsters":  "Hostile Creatures",
+			"global":    "Global",
+			"events":    "Events",
+			"survivors": "Survivors",
+			"world":     "World",
+			"portal":    "Portal Resources",
+			"giants":    "Giants",
+		},
+		keys: map[string]string{
+			"task_set":                   "Biomes",
+			"start_location":             "Spawn Area",
+			"world_size":                 "World Size",
+			"branching":                  "Branches",
+			"loop":                       "Loops",
+			"roads":                      "Roads",
+			"season_start":               "Starting Season",
+			"prefabswaps_start":          "Starting Resource Variety",
+			"touchstone":                 "Touch Stones",
+			"boons":                      "Skeletons",
+			"terrariumchest":             "Terrarium",
+			"moon_fissure":               "Celestial Fissures",
+			"grass":                      "Grass",
+			"sapling":                    "Saplings",
+			"marshbush":                  "Spiky Bushes",
+			"reeds":                      "Reeds",
+			"trees":                      "Trees",
+			"flint":                      "Flint",
+			"rock":                       "Boulders",
+			"mushroom":                   "Mushrooms",
+			"berrybush":                  "Berry Bushes",
+			"flowers":                    "Flowers",
+			"tumbleweed":                 "Tumbleweeds",
+			"rock_ice":                   "Mini Glaciers",
+			"meteorspawner":              "Meteor Fields",
+			"cactus":                     "Cacti",
+			"carrot":                     "Carrots",
+			"ponds":                      "Ponds",
+			"palmconetree":               "Palmcone Trees",
+			"moon_tree":                  "Lune Trees",
+			"moon_sapling":               "Lunar Saplings",
+			"moon_berrybush":             "Stone Fruit Bushes",
+			"moon_carrot":                "Carrats",
+			"moon_rock":                  "Lunar Rocks",
+			"moon_hotspring":             "Hot Springs",
+			"moon_starfish":              "Anenemies",
+			"moon_bullkelp":              "Beached Bull Kelp",
+			"ocean_bullkelp":             "Bull Kelp",
+			"ocean_shoal":                "Shoals",
+			"ocean_wobsterden":           "Wobster Mounds",
+			"ocean_seastack":             "Sea Stacks",
+			"ocean_waterplant":           "Sea Weeds",
+			"banana":                     "Cave Bananas",
+			"cave_ponds":                 "Ponds",
+			"fern":                       "Cave Ferns",
+			"fissure":                    "Nightmare Fissures",
+			"flower_cave":                "Light Flowers",
+			"lichen":                     "Lichen",
+			"mushtree":                   "Mushtrees",
+			"wormlights":                 "Glow Berries",
+			"rabbits":                    "Rabbits",
+			"moles":                      "Moles",
+			"beefalo":                    "Beefalo",
+			"lightninggoat":              "Volt Goats",
+			"bees":                       "Bees",
+			"catcoon":                    "Hollow Stumps",
+			"buzzard":                    "Buzzards",
+			"pigs":                       "Pigs",
+			"moon_fruitdragon":           "Saladmanders",
+			"bunnymen":                   "Bunnymen",
+			"monkey":                     "Splumonkeys",
+			"rocky":                      "Rock Lobsters",
+			"slurtles":                   "Slurtle Mounds",
+			"spiders":                    "Spiders",
+			"chess":                      "Clockwork Monsters",
+			"tentacles":                  "Tentacles",
+			"houndmound":                 "Hound Mounds",
+			"merm":                       "Leaky Shacks",
+			"angrybees":                  "Killer Bees",
+			"tallbirds":                  "Tallbirds",
+			"walrus":                     "MacTusk Camps",
+			"moon_spider":                "Shattered Spiders",
+			"bats":                       "Bats",
+			"slurper":                    "Slurpers",
+			"worms":                      "Depths Worms",
+			"cave_spiders":               "Spilagmites",
+			"specialevent":               "Events",
+			"autumn":                     "Autumn",
+			"winter":                     "Winter",
+			"spring":                     "Spring",
+			"summer":                     "Summer",
+			"day":                        "Day Type",
+			"beefaloheat":                "Beefalo Mating Frequency",
+			"krampus":                    "Krampii",
+			"crow_carnival":              "Midsummer Cawnival",
+			"hallowed_nights":            "Hallowed Nights",
+			"winters_feast":              "Winter's Feast",
+			"year_of_the_gobbler":        "Year of the Gobbler",
+			"year_of_the_varg":           "Year of the Varg",
+			"year_of_the_pig":            "Year of the Pig King",
+			"year_of_the_carrat":         "Year of the Carrat",
+			"year_of_the_beefalo":        "Year of the Beefalo",
+			"year_of_the_catcoon":        "Year of the Catcoon",
+			"year_of_the_bunnyman":       "Year of the Bunnyman",
+			"extrastartingitems":         "Extra Starting Resources",
+			"seasonalstartingitems":      "Seasonal Starting Items",
+			"spawnprotection":            "Griefer Spawn Protection",
+			"dropeverythingondespawn":    "Drop Items on Disconnect",
+			"shadowcreatures":            "Sanity Monsters",
+			"brightmarecreatures":        "Enlightenment Monsters",
+			"ghostenabled":               "Ghosts",
+			"ghostsanitydrain":           "Ghost Sanity Drain",
+			"portalresurection":          "Resurrect From Florid Postern",
+			"resettime":                  "Reset Timer",
+			"healthpenalty":              "Maximum Health Penalty",
+			"lessdamagetaken":            "Reduced Damage Taken",
+			"temperaturedamage":          "Temperature Damage",
+			"hunger":                     "Hunger Damage",
+			"darkness":                   "Darkness Damage",
+			"spawnmode":                  "Spawn Mode",
+			"regrowth":                   "Regrowth Multiplier",
+			"petrification":              "Forest Petrification",
+			"rifts_enabled":              "Rifts",
+			"rifts_enabled_cave":         "Rifts",
+			"stageplays":                 "Stage Plays",
+			"atriumgate":                 "Ancient Gateway",
+			"cavelight":                  "Sinkhole Lights",
+			"weather":                    "Rain",
+			"lightning":                  "Lightning",
+			"frograin":                   "Frog Rain",
+			"wildfires":                  "Wildfires",
+			"meteorshowers":              "Meteor Frequency",
+			"hunt":                       "Hunts",
+			"alternatehunt":              "Hunt Surprises",
+			"rifts_frequency":            "Rift Frequency",
+			"earthquakes":                "Earthquakes",
+			"rifts_frequency_cave":       "Rift Frequency",
+			"basicresource_regrowth":     "Basic Resource Regrowth",
+			"carrots_regrowth":           "Carrots",
+			"flowers_regrowth":           "Flowers",
+			"reeds_regrowth":             "Reeds",
+			"evergreen_regrowth":         "Evergreens",
+			"deciduoustree_regrowth":     "Birchnut Trees",
+			"twiggytrees_regrowth":       "Twiggy Trees",
+			"saltstack_regrowth":         "Salt Formations",
+			"cactus_regrowth":            "Cacti",
+			"palmconetree_regrowth":      "Palmcone Trees",
+			"moon_tree_regrowth":         "Lune Trees",
+			"mushtree_regrowth":          "Mushtrees",
+			"mushtree_moon_regrowth":     "Lunar Mushtrees",
+			"flower_cave_regrowth":       "Light Flowers",
+			"lightflier_flower_regrowth": "Lightbug Flowers",
+			"portal_spawnrate":           "Portal Spawn Rate",
+			"bananabush_portalrate":      "Banana Bushes",
+			"lightcrab_portalrate":       "Bioluminescence",
+			"monkeytail_portalrate":      "Monkey Tails",
+			"palmcone_seed_portalrate":   "Palmcone Sprouts",
+			"powder_monkey_portalrate":   "Powder Monkeys",
+			"bunnymen_setting":           "Bunnymen",
+			"grassgekkos":                "Grass Gekko Morphing",
+			"moles_setting":              "Moles",
+			"pigs_setting":               "Pigs",
+			"bees_setting":               "Bees",
+			"birds":                      "Birds",
+			"butterfly":                  "Butterflies",
+			"catcoons":                   "Catcoons",
+			"fishschools":                "Schools of Fish",
+			"penguins":                   "Pengulls",
+			"perd":                       "Gobblers",
+			"rabbits_setting":            "Rabbits",
+			"wobsters":                   "Wobsters",
+			"dustmoths":                  "Dust Moths",
+			"lightfliers":                "Bulbous Lightbugs",
+			"monkey_setting":             "Splumonkeys",
+			"rocky_setting":              "Rock Lobsters",
+			"slurtles_setting":           "Slurtles",
+			"snurtles":                   "Snurtles",
+			"bats_setting":               "Bats",
+			"liefs":                      "Treeguards",
+			"merms":                      "Merms",
+			"spider_warriors":            "Spider Warriors",
+			"spiders_setting":            "Spiders",
+			"hounds":                     "Hound Attacks",
+			"hound_mounds":               "Hound Mounds",
+			"lureplants":                 "Lureplants",
+			"moon_spiders":               "Shattered Spiders",
+			"mutated_hounds":             "Horror Hounds",
+			"penguins_moon":              "Moonrock Pengulls",
+			"pirateraids":                "Moon Quay Pirates",
+			"sharks":                     "Sharks",
+			"squid":                      "Skittersquids",
+			"wasps":                      "Killer Bees",
+			"walrus_setting":             "MacTusk",
+			"cookiecutters":              "Cookie Cutters",
+			"frogs":                      "Frogs",
+			"gnarwail":                   "Gnarwails",
+			"mosquitos":                  "Mosquitos",
+			"deciduousmonster":           "Poison Birchnut Trees",
+			"summerhounds":               "Fire Hounds",
+			"winterhounds":               "Ice Hounds",
+			"molebats":                   "Naked Mole Bats",
+			"mushgnome":                  "Mush Gnomes",
+			"nightmarecreatures":         "Ruins Nightmares",
+			"spider_dropper":             "Dangling Depth Dwellers",
+			"spider_hider":               "Cave Spiders",
+			"spider_spitter":             "Spitter Spiders",
+			"wormattacks":                "Depths Worm Attacks",
+			"fruitfly":                   "Lord of the Fruit Flies",
+			"spiderqueen":                "Spider Queens",
+			"antliontribute":             "Antlion Tributes",
+			"bearger":                    "Bearger",
+			"beequeen":                   "Bee Queen",
+			"crabking":                   "Crab King",
+			"deerclops":                  "Deerclops",
+			"dragonfly":                  "Dragonfly",
+			"eyeofterror":                "Eye of Terror",
+			"goosemoose":                 "Moose/Goose",
+			"klaus":                      "Klaus",
+			"malbatross":                 "Malbatross",
+			"toadstool":                  "Toadstool",
+			"daywalker":                  "Nightmare Werepig",
+		},
+		values: map[string]string{
+			"never":           "None",
+			"rare":            "Less",
+			"default":         "Default",
+			"often":           "More",
+			"always":          "Lots",
+			"veryslow":        "Very Slow",
+			"slow":            "Slow",
+			"fast":            "Fast",
+			"veryfast":        "Very Fast",
+			"noseason":        "None",
+			"veryshortseason": "Very Short",
+			"shortseason":     "Short",
+			"longseason":      "Long",
+			"verylongseason":  "Very Long",
+			"random":          "Random",
+			"longday":         "Long Day",
+			"longdusk":        "Long Dusk",
+			"longnight":       "Long Night",
+			"noday":           "No Day",
+			"nodusk":          "No Dusk",
+			"nonight":         "No Night",
+			"onlyday":         "Only Day",
+			"onlydusk":        "Only Dusk",
+			"onlynight":       "Only Night",
+			"none":            "None",
+			"enabled":         "Enabled",
+			"nonlethal":       "Non-lethal",
+			"few":             "Few",
+			"many":            "Many",
+			"max":             "Max",
+			"ocean_never":     "None",
+			"ocean_rare":      "Little",
+			"ocean_uncommon":  "Less",
+			"ocean_default":   "Default",
+			"ocean_often":     "More",
+			"ocean_mostly":    "Lots",
+			"ocean_always":    "Tons",
+			"ocean_insane":    "Insane",
+			"true":            "Yes",
+			"false":           "No",
+		},
+		categoryValues: map[OverrideCategory]map[string]string{
+			OverrideWorldGen: {
+				"never":    "None",
+				"rare":     "Little",
+				"uncommon": "Less",
+				"default":  "Default",
+				"often":    "More",
+				"mostly":   "Lots",
+				"always":   "Tons",
+				"insane":   "Insane",
+			},
+		},
+		keyValues: map[string]map[string]string{
+			"task_set": {
+				"default":           "Together",
+				"classic":           "Classic",
+				"cave_default":      "Underground",
+				"lavaarena_taskset": "The Forge",
+				"quagmire_taskset":  "The Gorge",
+			},
+			"start_location": {
+				"plus":                   "Plus",
+				"darkness":               "Dark",
+				"caves":                  "Caves",
+				"lavaarena":              "The Forge",
+				"quagmire_startlocation": "The Gorge",
+			},
+			"world_size": {"small": "Small", "medium": "Medium", "default": "Large", "huge": "Huge"},
+			"branching":  {"never": "Never", "least": "Least", "most": "Most"},
+			"loop":       {"never": "Never", "always": "Always"},
+			"season_start": {
+				"default":        "Autumn",
+				"winter":         "Winter",
+				"spring":         "Spring",
+				"summer":         "Summer",
+				"autumnorspring": "Autumn or Spring",
+				"winterorsummer": "Winter or Summer",
+			},
+			"prefabswaps_start": {"classic": "Classic", "highlyrandom": "Highly Random"},
+			"specialevent": {
+				"default":               "Auto",
+				"hallowed_nights":       "Hallowed Nights",
+				"winters_feast":         "Winter's Feast",
+				"year_of_the_gobbler":   "Year of the Gobbler",
+				"year_of_the_varg":      "Year of the Varg",
+				"year_of_the_pig":       "Year of the Pig King",
+				"year_of_the_carrat":    "Year of the Carrat",
+				"year_of_the_beefalo":   "Year of the Beefalo",
+				"year_of_the_catcoon":   "Year of the Catcoon",
+				"year_of_the_bunnyman":  "Year of the Bunnyman",
+				"year_of_the_dragonfly": "Year of the Dragonfly",
+				"crow_carnival":         "Midsummer Cawnival",
+			},
+			"extrastartingitems": {
+				"0":       "Always",
+				"5":       "After Day 5",
+				"default": "After Day 10",
+				"15":      "After Day 15",
+				"20":      "After Day 20",
+				"none":    "Never",
+			},
+			"spawnprotection":         {"default": "Auto", "always": "Always"},
+			"dropeverythingondespawn": {"always": "Everything"},
+			"resettime":               {"always": "Instant"},
+			"spawnmode":               {"fixed": "Florid Postern", "scatter": "Random"},
+			"ghostenabled":            toggleValuesEn,
+			"ghostsanitydrain":        toggleValuesEn,
+			"portalresurection":       toggleValuesEn,
+			"healthpenalty":           toggleValuesEn,
+			"lessdamagetaken":         toggleValuesEn,
+			"basicresource_regrowth":  toggleValuesEn,
+			"rifts_enabled":           riftValuesEn,
+			"rifts_enabled_cave":      riftValuesEn,
+		},
+	},
+	LocaleZh: {
+		categories: map[OverrideCategory]string{
+			OverrideWorldGen: "世界生成",
+			OverrideSettings: "世界设置",
+		},
+		groups: map[string]string{
+			"misc":      "世界",
+			"resources": "资源",
+			"animals":   "生物",
+			"monsters":  "敌对生物",
+			"global":    "全局",
+			"events":    "活动",
+			"survivors": "冒险家",
+			"world":     "世界",
+			"portal":    "传送门资源",
+			"giants":    "巨兽",
+		},
+		keys: map[string]string{
+			"task_set":                   "生物群落",
+			"start_location":             "出生点",
+			"world_size":                 "世界大小",
+			"branching":                  "分支",
+			"loop":                       "环形",
+			"roads":                      "道路",
+			"season_start":               "起始季节",
+			"prefabswaps_start":          "开始资源多样化",
+			"touchstone":                 "试金石",
+			"boons":                      "骨架",
+			"terrariumchest":             "盒中泰拉",
+			"moon_fissure":               "天体裂隙",
+			"grass":                      "草",
+			"sapling":                    "树苗",
+			"marshbush":                  "尖刺灌木",
+			"reeds":                      "芦苇",
+			"trees":                      "树",
+			"flint":                      "燧石",
+			"rock":                       "巨石",
+			"mushroom":                   "蘑菇",
+			"berrybush":                  "浆果丛",
+			"flowers":                    "花",
+			"tumbleweed":                 "风滚草",
+			"rock_ice":                   "迷你冰川",
+			"meteorspawner":              "流星区域",
+			"cactus":                     "仙人掌",
+			"carrot":                     "胡萝卜",
+			"ponds":                      "池塘",
+			"palmconetree":               "棕榈松果树",
+			"moon_tree":                  "月树",
+			"moon_sapling":               "月亮树苗",
+			"moon_berrybush":             "石果灌木丛",
+			"moon_carrot":                "胡萝卜鼠",
+			"moon_rock":                  "月亮石",
+			"moon_hotspring":             "温泉",
+			"moon_starfish":              "海星",
+			"moon_bullkelp":              "海岸公牛海带",
+			"ocean_bullkelp":             "公牛海带",
+			"ocean_shoal":                "鱼群",
+			"ocean_wobsterden":           "龙虾窝",
+			"ocean_seastack":             "浮堆",
+			"ocean_waterplant":           "海草",
+			"banana":                     "洞穴香蕉",
+			"cave_ponds":                 "池塘",
+			"fern":                       "洞穴蕨类",
+			"fissure":                    "梦魇裂隙",
+			"flower_cave":                "荧光花",
+			"lichen":                     "苔藓",
+			"mushtree":                   "蘑菇树",
+			"wormlights":                 "发光浆果",
+			"rabbits":                    "兔子",
+			"moles":                      "鼹鼠",
+			"beefalo":                    "皮弗娄牛",
+			"lightninggoat":              "伏特羊",
+			"bees":                       "蜜蜂",
+			"catcoon":                    "空心树桩",
+			"buzzard":                    "秃鹫",
+			"pigs":                       "猪",
+			"moon_fruitdragon":           "沙拉蝾螈",
+			"bunnymen":                   "兔人",
+			"monkey":                     "穴居猴",
+			"rocky":                      "石虾",
+			"slurtles":                   "蛞蝓龟窝",
+			"spiders":                    "蜘蛛",
+			"chess":                      "发条装置",
+			"tentacles":                  "触手",
+			"houndmound":                 "猎犬丘",
+			"merm":                       "漏雨的小屋",
+			"angrybees":                  "杀人蜂",
+			"tallbirds":                  "高脚鸟",
+			"walrus":                     "海象营地",
+			"moon_spider":                "破碎蜘蛛",
+			"bats":                       "蝙蝠",
+			"slurper":                    "啜食者",
+			"worms":                      "洞穴蠕虫",
+			"cave_spiders":               "洞穴蜘蛛",
+			"specialevent":               "活动",
+			"autumn":                     "秋天",
+			"winter":                     "冬天",
+			"spring":                     "春天",
+			"summer":                     "夏天",
+			"day":                        "昼夜选项",
+			"beefaloheat":                "皮弗娄牛交配频率",
+			"krampus":                    "坎普斯",
+			"crow_carnival":              "盛夏鸦年华",
+			"hallowed_nights":            "万圣夜",
+			"winters_feast":              "冬季盛宴",
+			"year_of_the_gobbler":        "火鸡之年",
+			"year_of_the_varg":           "座狼之年",
+			"year_of_the_pig":            "猪王之年",
+			"year_of_the_carrat":         "胡萝卜鼠之年",
+			"year_of_the_beefalo":        "皮弗娄牛之年",
+			"year_of_the_catcoon":        "浣猫之年",
+			"year_of_the_bunnyman":       "兔人之年",
+			"extrastartingitems":         "额外起始资源",
+			"seasonalstartingitems":      "季节起始物品",
+			"spawnprotection":            "防骚扰出生保护",
+			"dropeverythingondespawn":    "离开游戏后物品掉落",
+			"shadowcreatures":            "理智怪兽",
+			"brightmarecreatures":        "启蒙怪兽",
+			"ghostenabled":               "鬼魂",
+			"ghostsanitydrain":           "鬼魂理智值下降",
+			"portalresurection":          "在绚丽之门复活",
+			"resettime":                  "重置时间",
+			"healthpenalty":              "最大生命值惩罚",
+			"lessdamagetaken":            "受到的伤害减少",
+			"temperaturedamage":          "温度伤害",
+			"hunger":                     "饥饿伤害",
+			"darkness":                   "黑暗伤害",
+			"spawnmode":                  "出生模式",
+			"regrowth":                   "再生速度",
+			"petrification":              "森林石化",
+			"rifts_enabled":              "裂隙",
+			"rifts_enabled_cave":         "裂隙",
+			"stageplays":                 "舞台剧",
+			"atriumgate":                 "远古大门",
+			"cavelight":                  "洞穴光照",
+			"weather":                    "雨",
+			"lightning":                  "闪电",
+			"frograin":                   "青蛙雨",
+			"wildfires":                  "野火",
+			"meteorshowers":              "流星频率",
+			"hunt":                       "狩猎",
+			"alternatehunt":              "追猎惊喜",
+			"rifts_frequency":            "裂隙频率",
+			"earthquakes":                "地震",
+			"rifts_frequency_cave":       "裂隙频率",
+			"basicresource_regrowth":     "基础资源再生",
+			"carrots_regrowth":           "胡萝卜",
+			"flowers_regrowth":           "花",
+			"reeds_regrowth":             "芦苇",
+			"evergreen_regrowth":         "常青树",
+			"deciduoustree_regrowth":     "桦栗树",
+			"twiggytrees_regrowth":       "多枝树",
+			"saltstack_regrowth":         "盐堆",
+			"cactus_regrowth":            "仙人掌",
+			"palmconetree_regrowth":      "棕榈松果树",
+			"moon_tree_regrowth":         "月树",
+			"mushtree_regrowth":          "蘑菇树",
+			"mushtree_moon_regrowth":     "月亮蘑菇树",
+			"flower_cave_regrowth":       "荧光花",
+			"lightflier_flower_regrowth": "球状光虫花",
+			"portal_spawnrate":           "传送门生成速度",
+			"bananabush_portalrate":      "香蕉丛",
+			"lightcrab_portalrate":       "生物发光",
+			"monkeytail_portalrate":      "猴尾草",
+			"palmcone_seed_portalrate":   "棕榈松果树芽",
+			"powder_monkey_portalrate":   "火药猴",
+			"bunnymen_setting":           "兔人",
+			"grassgekkos":                "草壁虎转化",
+			"moles_setting":              "鼹鼠",
+			"pigs_setting":               "猪",
+			"bees_setting":               "蜜蜂",
+			"birds":                      "鸟",
+			"butterfly":                  "蝴蝶",
+			"catcoons":                   "浣猫",
+			"fishschools":                "鱼群",
+			"penguins":                   "企鸥",
+			"perd":                       "火鸡",
+			"rabbits_setting":            "兔子",
+			"wobsters":                   "龙虾",
+			"dustmoths":                  "尘蛾",
+			"lightfliers":                "球状光虫",
+			"monkey_setting":             "穴居猴",
+			"rocky_setting":              "石虾",
+			"slurtles_setting":           "蛞蝓龟",
+			"snurtles":                   "蜗牛龟",
+			"bats_setting":               "蝙蝠",
+			"liefs":                      "树精守卫",
+			"merms":                      "鱼人",
+			"spider_warriors":            "蜘蛛战士",
+			"spiders_setting":            "蜘蛛",
+			"hounds":                     "猎犬袭击",
+			"hound_mounds":               "猎犬丘",
+			"lureplants":                 "食人花",
+			"moon_spiders":               "破碎蜘蛛",
+			"mutated_hounds":             "恐怖猎犬",
+			"penguins_moon":              "月岩企鸥",
+			"pirateraids":                "月亮码头海盗",
+			"sharks":                     "鲨鱼",
+			"squid":                      "鱿鱼",
+			"wasps":                      "杀人蜂",
+			"walrus_setting":             "海象",
+			"cookiecutters":              "饼干切割机",
+			"frogs":                      "青蛙",
+			"gnarwail":                   "一角鲸",
+			"mosquitos":                  "蚊子",
+			"deciduousmonster":           "毒桦栗树",
+			"summerhounds":               "红色猎犬",
+			"winterhounds":               "蓝色猎犬",
+			"molebats":                   "裸鼹蝠",
+			"mushgnome":                  "蘑菇地精",
+			"nightmarecreatures":         "遗迹梦魇",
+			"spider_dropper":             "穴居悬蛛",
+			"spider_hider":               "洞穴蜘蛛",
+			"spider_spitter":             "喷射蜘蛛",
+			"wormattacks":                "洞穴蠕虫攻击",
+			"fruitfly":                   "果蝇王",
+			"spiderqueen":                "蜘蛛女王",
+			"antliontribute":             "蚁狮贡品",
+			"bearger":                    "熊獾",
+			"beequeen":                   "蜂王",
+			"crabking":                   "帝王蟹",
+			"deerclops":                  "独眼巨鹿",
+			"dragonfly":                  "龙蝇",
+			"eyeofterror":                "恐怖之眼",
+			"goosemoose":                 "麋鹿鹅",
+			"klaus":                      "克劳斯",
+			"malbatross":                 "邪天翁",
+			"toadstool":                  "蟾蜍王",
+			"daywalker":                  "梦魇疯猪",
+		},
+		values: map[string]string{
+			"never":           "无",
+			"rare":            "较少",
+			"default":         "默认",
+			"often":           "较多",
+			"always":          "大量",
+			"veryslow":        "极慢",
+			"slow":            "慢",
+			"fast":            "快",
+			"veryfast":        "极快",
+			"noseason":        "无",
+			"veryshortseason": "极短",
+			"shortseason":     "短",
+			"longseason":      "长",
+			"verylongseason":  "极长",
+			"random":          "随机",
+			"longday":         "长白天",
+			"longdusk":        "长黄昏",
+			"longnight":       "长夜晚",
+			"noday":           "无白天",
+			"nodusk":          "无黄昏",
+			"nonight":         "无夜晚",
+			"onlyday":         "仅白天",
+			"onlydusk":        "仅黄昏",
+			"onlynight":       "仅夜晚",
+			"none":            "无",
+			"enabled":         "启用",
+			"nonlethal":       "无伤害",
+			"few":             "少",
+			"many":            "多",
+			"max":             "最多",
+			"ocean_never":     "无",
+			"ocean_rare":      "很少",
+			"ocean_uncommon":  "较少",
+			"ocean_default":   "默认",
+			"ocean_often":     "较多",
+			"ocean_mostly":    "很多",
+			"ocean_always":    "大量",
+			"ocean_insane":    "疯狂",
+			"true":            "是",
+			"false":           "否",
+		},
+		categoryValues: map[OverrideCategory]map[string]string{
+			OverrideWorldGen: {
+				"never":    "无",
+				"rare":     "很少",
+				"uncommon": "较少",
+				"default":  "默认",
+				"often":    "较多",
+				"mostly":   "很多",
+				"always":   "大量",
+				"insane":   "疯狂",
+			},
+		},
+		keyValues: map[string]map[string]string{
+			"task_set": {
+				"default":           "联机版",
+				"classic":           "经典",
+				"cave_default":      "地下",
+				"lavaarena_taskset": "熔炉",
+				"quagmire_taskset":  "暴食",
+			},
+			"start_location": {
+				"plus":                   "额外资源",
+				"darkness":               "黑暗",
+				"caves":                  "洞穴",
+				"lavaarena":              "熔炉",
+				"quagmire_startlocation": "暴食",
+			},
+			"world_size": {"small": "小", "medium": "中", "default": "大", "huge": "巨大"},
+			"branching":  {"never": "从不", "least": "最少", "most": "最多"},
+			"loop":       {"never": "从不", "always": "总是"},
+			"season_start": {
+				"default":        "秋天",
+				"winter":         "冬天",
+				"spring":         "春天",
+				"summer":         "夏天",
+				"autumnorspring": "秋天或春天",
+				"winterorsummer": "冬天或夏天",
+			},
+			"prefabswaps_start": {"classic": "经典", "highlyrandom": "高度随机"},
+			"specialevent": {
+				"default":               "自动",
+				"hallowed_nights":       "万圣夜",
+				"winters_feast":         "冬季盛宴",
+				"year_of_the_gobbler":   "火鸡之年",
+				"year_of_the_varg":      "座狼之年",
+				"year_of_the_pig":       "猪王之年",
+				"year_of_the_carrat":    "胡萝卜鼠之年",
+				"year_of_the_beefalo":   "皮弗娄牛之年",
+				"year_of_the_catcoon":   "浣猫之年",
+				"year_of_the_bunnyman":  "兔人之年",
+				"year_of_the_dragonfly": "龙蝇之年",
+				"crow_carnival":         "盛夏鸦年华",
+			},
+			"extrastartingitems": {
+				"0":       "总是",
+				"5":       "第5天后",
+				"default": "第10天后",
+				"15":      "第15天后",
+				"20":      "第20天后",
+				"none":    "从不",
+			},
+			"spawnprotection":         {"default": "自动", "always": "总是"},
+			"dropeverythingondespawn": {"always": "所有"},
+			"resettime":               {"always": "立即"},
+			"spawnmode":               {"fixed": "绚丽之门", "scatter": "随机"},
+			"ghostenabled":            toggleValuesZh,
+			"ghostsanitydrain":        toggleValuesZh,
+			"portalresurection":       toggleValuesZh,
+			"healthpenalty":           toggleValuesZh,
+			"lessdamagetaken":         toggleValuesZh,
+			"basicresource_regrowth":  toggleValuesZh,
+			"rifts_enabled":           riftValuesZh,
+			"rifts_enabled_cave":      riftValuesZh,
+		},
+	},
+}
+
+// Locales returns the locales which have override labels
+func Locales() []string {
+	return []string{LocaleEn, LocaleZh}
+}
+
+// normalizeLocale returns the locale of labels, egs. zh_CN -> zh, zhr -> zh
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	if alias, ok := localeAliases[locale]; ok {
+		return alias
+	}
+	return locale
+}
+
+// lookupOverrideLabel looks up label in locale first then en, returns empty if not found
+func lookupOverrideLabel(locale string, lookup func(labels overrideLabels) string) string {
+	for _, l := range []string{normalizeLocale(locale), LocaleEn} {
+		if label := lookup(overrideLabelCatalog[l]); len(label) > 0 {
+			return label
+		}
+	}
+	return ""
+}
+
+// Label returns the display name of category in locale, egs. World Generation
+func (c OverrideCategory) Label(locale string) string {
+	if label := lookupOverrideLabel(locale, func(labels overrideLabels) string {
+		return labels.categories[c]
+	}); len(label) > 0 {
+		return label
+	}
+	return string(c)
+}
+
+// OverrideGroupLabel returns the display name of group in customization screen, egs. giants -> Giants
+func OverrideGroupLabel(locale, group string) string {
+	if label := lookupOverrideLabel(locale, func(labels overrideLabels) string {
+		return labels.groups[group]
+	}); len(label) > 0 {
+		return label
+	}
+	return group
+}
+
+// Label returns the display name of key in locale, falls back to en then the key name
+func (k OverrideKey) Label(locale string) string {
+	if label := lookupOverrideLabel(locale, func(labels overrideLabels) string {
+		return labels.keys[k.Name]
+	}); len(label) > 0 {
+		return label
+	}
+	return k.Name
+}
+
+// ValueLabel returns the display name of value of key in locale, egs. "often" of grass -> More,
+// falls back to en then the value itself.
+func (k OverrideKey) ValueLabel(locale string, value any) string {
+	v := fmt.Sprint(value)
+	if label := lookupOverrideLabel(locale, func(labels overrideLabels) string {
+		if label, ok := labels.keyValues[k.Name][v]; ok {
+			return label
+		}
+		if label, ok := labels.categoryValues[k.Category][v]; ok {
+			return label
+		}
+		return labels.values[v]
+	}); len(label) > 0 {
+		return label
+	}
+	return v
+}
@@ -0,0 +1,57 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOverrideLabelCatalog(t *testing.T) {
+	for _, key := range OverrideKeys("") {
+		if key.Hidden {
+			continue
+		}
+		en, zh := key.Label(LocaleEn), key.Label(LocaleZh)
+		assert.NotEqual(t, key.Name, en, key.Name)
+		assert.NotEqual(t, en, zh, key.Name)
+		for _, value := range key.Values {
+			en, zh := key.ValueLabel(LocaleEn, value), key.ValueLabel(LocaleZh, value)
+			assert.NotEqual(t, value, en, key.Name)
+			assert.NotEqual(t, en, zh, key.Name)
+		}
+		assert.NotEqual(t, key.Group, OverrideGroupLabel(LocaleEn, key.Group), key.Name)
+		assert.NotEqual(t, OverrideGroupLabel(LocaleEn, key.Group), OverrideGroupLabel(LocaleZh, key.Group), key.Name)
+	}
+}
+
+func TestOverrideLabel(t *testing.T) {
+	key, ok := LookupOverrideKey("brightmarecreatures")
+	assert.True(t, ok)
+	assert.Equal(t, "Enlightenment Monsters", key.Label(LocaleEn))
+	assert.Equal(t, "启蒙怪兽", key.Label(LocaleZh))
+	assert.Equal(t, "More", key.ValueLabel(LocaleEn, "often"))
+	assert.Equal(t, "较多", key.ValueLabel("zh_CN", "often"))
+
+	// worldgen amounts are labelled differently
+	key, _ = LookupOverrideKey("grass")
+	assert.Equal(t, "Little", key.ValueLabel(LocaleEn, "rare"))
+	key, _ = LookupOverrideKey("ghostenabled")
+	assert.Equal(t, "Enabled", key.ValueLabel(LocaleEn, "always"))
+
+	assert.Equal(t, "World Settings", OverrideSettings.Label(LocaleEn))
+	assert.Equal(t, "世界生成", OverrideWorldGen.Label("zhr"))
+	assert.Equal(t, "巨兽", OverrideGroupLabel(LocaleZh, "giants"))
+}
+
+func TestOverrideLabelFallback(t *testing.T) {
+	// unsupported locale falls back to en
+	key, _ := LookupOverrideKey("day")
+	assert.Equal(t, "Day Type", key.Label("fr"))
+	assert.Equal(t, "Long Dusk", key.ValueLabel("fr", "longdusk"))
+
+	// unknown keys and values fall back to themselves
+	key = OverrideKey{Name: "my_mod_key"}
+	assert.Equal(t, "my_mod_key", key.Label(LocaleZh))
+	assert.Equal(t, "my_value", key.ValueLabel(LocaleZh, "my_value"))
+	assert.Equal(t, "Yes", key.ValueLabel(LocaleEn, true))
+	assert.Equal(t, "my_group", OverrideGroupLabel(LocaleZh, "my_group"))
+}
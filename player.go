@@ -1,8 +1,12 @@
 package dstparser
 
 import (
-	"strconv"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
 	"strings"
+	"time"
 	"unsafe"
 )
 
@@ -20,6 +24,111 @@ func ToPlayerTxt(kleiIDs []string) ([]byte, error) {
 	return unsafe.Slice(unsafe.StringData(joinStr), len(joinStr)), nil
 }
 
+// PlayerListKind represents which player list file is
+type PlayerListKind string
+
+const (
+	AdminList PlayerListKind = "adminlist"
+	WhiteList PlayerListKind = "whitelist"
+	BlockList PlayerListKind = "blocklist"
+)
+
+// FileName returns the file name of the player list in cluster folder
+func (k PlayerListKind) FileName() string {
+	return string(k) + ".txt"
+}
+
+// PlayerList represents adminlist.txt, whitelist.txt or blocklist.txt, it holds unique klei ids in order.
+type PlayerList struct {
+	Kind PlayerListKind
+	ids  []string
+}
+
+// NewPlayerList returns a player list with given klei ids, duplicated ids are ignored.
+func NewPlayerList(kind PlayerListKind, kleiIDs ...string) (*PlayerList, error) {
+	list := &PlayerList{Kind: kind}
+	for _, id := range kleiIDs {
+		if err := list.Add(id); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// ParsePlayerList parses player list file, it tolerates BOM, CRLF line endings, blank lines
+// and comment lines starting with # or ;, duplicated ids are ignored.
+func ParsePlayerList(kind PlayerListKind, content []byte) (*PlayerList, error) {
+	content = bytes.TrimPrefix(content, utf8BOM)
+	list := &PlayerList{Kind: kind}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if err := list.Add(line); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", kind.FileName(), i+1, err)
+		}
+	}
+	return list, nil
+}
+
+// IsKleiID reports whether id is a valid klei id like KU_xxxxxxxx
+func IsKleiID(id string) bool {
+	return strings.HasPrefix(id, "KU_") && len(id) > len("KU_") && tokenCharset(id[len("KU_"):], "_-")
+}
+
+// Add appends the klei id to list if not exist
+func (l *PlayerList) Add(kleiID string) error {
+	if !IsKleiID(kleiID) {
+		return fmt.Errorf("invalid klei id: %q", kleiID)
+	}
+	if !l.Contains(kleiID) {
+		l.ids = append(l.ids, kleiID)
+	}
+	return nil
+}
+
+// Remove removes the klei id from list, returns false if not exist
+func (l *PlayerList) Remove(kleiID string) bool {
+	for i, id := range l.ids {
+		if id == kleiID {
+			l.ids = append(l.ids[:i], l.ids[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether the klei id is in list
+func (l *PlayerList) Contains(kleiID string) bool {
+	for _, id := range l.ids {
+		if id == kleiID {
+			return true
+		}
+	}
+	return false
+}
+
+// IDs returns a copy of klei ids in list
+func (l *PlayerList) IDs() []string {
+	return append([]string(nil), l.ids...)
+}
+
+// Len returns the number of ids in list
+func (l *PlayerList) Len() int {
+	return len(l.ids)
+}
+
+// ToPlayerListTxt converts player list to the file format, one klei id per line with LF line ending
+func ToPlayerListTxt(list *PlayerList) []byte {
+	buffer := bytes.NewBuffer(nil)
+	for _, id := range list.ids {
+		buffer.WriteString(id)
+		buffer.WriteByte('\n')
+	}
+	return buffer.Bytes()
+}
+
 // ChatLog represents a chat record in server_chat_log.txt
 type ChatLog struct {
 	Time   string `mapstructure:"time"`
@@ -27,18 +136,35 @@ type ChatLog struct {
 	KleiId string `mapstructure:"klei_id"`
 	Name   string `mapstructure:"player_name"`
 	Msg    string `mapstructure:"msg"`
-}
-
-func trim(s, l, r string) string {
-	s = strings.TrimLeft(s, l)
-	s = strings.TrimRight(s, r)
-	return s
+	// the original line in log file
+	Raw string `mapstructure:"raw"`
+	// Time as the elapsed duration since server started
+	Offset time.Duration `mapstructure:"offset"`
+	// absolute time of the record, only available after StampChatLogs
+	Timestamp time.Time `mapstructure:"timestamp"`
 }
 
 func surround(bs string, l, r string) bool {
 	return strings.HasPrefix(bs, l) && strings.HasSuffix(bs, r)
 }
 
+// chatLinePattern matches [00:01:18]: [Type] body
+var chatLinePattern = regexp.MustCompile(`^\[(\d+:\d{2}:\d{2})\]: \[([^\]]+)\] ?(.*)$`)
+
+// ChatLogSyntaxError reports a line in server_chat_log.txt which can not be parsed
+type ChatLogSyntaxError struct {
+	// line number, starts from 1
+	Line   int
+	Raw    string
+	Reason string
+}
+
+func (e *ChatLogSyntaxError) Error() string {
+	return fmt.Sprintf("chat log line %d: %s: %q", e.Line, e.Reason, e.Raw)
+}
+
+// ParseServerChatLogs parses server_chat_log.txt, returns all parsed chat logs.
+// Lines that can not be parsed are skipped and reported as *ChatLogSyntaxError joined in the returned error.
 func ParseServerChatLogs(content []byte) ([]ChatLog, error) {
 	if len(content) == 0 {
 		return nil, nil
@@ -46,62 +172,118 @@ func ParseServerChatLogs(content []byte) ([]ChatLog, error) {
 
 	logsStr := unsafe.String(unsafe.SliceData(content), len(content))
 
-	var logs []ChatLog
-	lines := strings.Split(logsStr, "\n")
-	for _, line := range lines {
-		var log ChatLog
-		records := strings.Split(line, ": ")
-		if len(records) > 0 {
-			log.Time = trim(records[0], "[", "]")
+	var (
+		logs   []ChatLog
+		errs   []error
+		parser chatLogParser
+	)
+	for i, line := range strings.Split(logsStr, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		log, err := parser.parseLine(line)
+		if err != nil {
+			errs = append(errs, &ChatLogSyntaxError{Line: i + 1, Raw: line, Reason: err.Error()})
+			continue
 		}
+		logs = append(logs, log)
+	}
+
+	return logs, errors.Join(errs...)
+}
+
+// chatLogParser parses chat log lines one by one, it remembers the player names seen in announcements,
+// which are used to delimit names containing ": " in chat messages.
+type chatLogParser struct {
+	names map[string]struct{}
+}
+
+func (p *chatLogParser) remember(name string) {
+	if p.names == nil {
+		p.names = make(map[string]struct{})
+	}
+	p.names[name] = struct{}{}
+}
 
-		if len(records) > 1 {
-			record := strings.Join(records[1:], ": ")
-
-			sql := strings.Index(record, "[")
-			sqr := strings.Index(record, "]")
-
-			// log type
-			if sqr > sql {
-				log.Type = strings.TrimSpace(record[sql+1 : sqr])
-			}
-
-			if log.Type == "Vote Announcement" {
-				log.Msg = strings.TrimSpace(record[sqr+1:])
-				logs = append(logs, log)
-				continue
-			}
-
-			bkl := strings.Index(record, "(")
-			bkr := strings.Index(record, ")")
-
-			// klei id
-			if bkr > bkl {
-				kid := strings.TrimSpace(record[bkl+1 : bkr])
-				if strings.HasPrefix(kid, "KU_") {
-					log.KleiId = kid
-				}
-			}
-
-			idx := sqr
-			if bkr > 0 && len(log.KleiId) > 0 {
-				idx = bkr
-			}
-
-			// name
-			fields := strings.Split(strings.TrimSpace(record[idx+1:]), " ")
-			if len(fields) > 0 {
-				log.Name = strings.TrimRight(fields[0], ":")
-			}
-
-			// msg
-			if len(fields) > 1 {
-				log.Msg = strconv.Quote(strings.TrimSpace(strings.Join(fields[1:], " ")))
-			}
+func (p *chatLogParser) parseLine(line string) (ChatLog, error) {
+	match := chatLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return ChatLog{}, errors.New("missing [time]: [type] prefix")
+	}
+
+	log := ChatLog{Time: match[1], Type: match[2], Raw: line}
+	body := match[3]
+
+	offset, err := ParseLogOffset(log.Time)
+	if err != nil {
+		return ChatLog{}, err
+	}
+	log.Offset = offset
+
+	switch log.Type {
+	case ChatTypeSay, ChatTypeWhisper:
+		// (KU_xxx) name: message
+		end := strings.Index(body, ") ")
+		if !strings.HasPrefix(body, "(") || end < 0 {
+			return ChatLog{}, errors.New("missing klei id")
+		}
+		log.KleiId = body[1:end]
+		name, msg, ok := p.splitName(body[end+2:])
+		if !ok {
+			return ChatLog{}, errors.New("missing message")
+		}
+		log.Name, log.Msg = name, msg
+	case ChatTypeJoin, ChatTypeLeave:
+		if len(body) == 0 {
+			return ChatLog{}, errors.New("missing player name")
+		}
+		log.Name = body
+		p.remember(body)
+	case ChatTypeDeath, ChatTypeResurrect:
+		// name marker rest
+		markers := deathMarkers
+		if log.Type == ChatTypeResurrect {
+			markers = resurrectMarkers
+		}
+		name, rest, ok := splitMarker(body, markers)
+		if !ok {
+			// markers of other languages are unknown, keep the whole body
+			log.Msg = body
+			break
 		}
+		log.Name, log.Msg = name, rest
+		p.remember(name)
+	case ChatTypeRoll:
+		// (KU_xxx) name 83 (1-100)
+		roll := rollPattern.FindStringSubmatch(body)
+		if roll == nil {
+			return ChatLog{}, errors.New("unknown roll format")
+		}
+		log.KleiId, log.Name, log.Msg = roll[1], roll[2], roll[3]
+	default:
+		log.Msg = body
+	}
 
-		logs = append(logs, log)
+	return log, nil
+}
+
+// splitName splits name: message, prefers the longest known name if the name contains ": "
+func (p *chatLogParser) splitName(s string) (name, msg string, ok bool) {
+	for known := range p.names {
+		if len(known) > len(name) && strings.HasPrefix(s, known+":") {
+			name = known
+		}
+	}
+	if len(name) > 0 {
+		return name, strings.TrimPrefix(s[len(name)+1:], " "), true
 	}
 
-	return logs, nil
+	if name, msg, ok = strings.Cut(s, ": "); ok {
+		return name, msg, len(name) > 0
+	}
+	if strings.HasSuffix(s, ":") && len(s) > 1 {
+		return s[:len(s)-1], "", true
+	}
+	return "", "", false
 }
@@ -1,6 +1,7 @@
 package dstparser
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -21,4 +22,66 @@ func TestParseServerChatLogs(t *testing.T) {
 	logs, err := ParseServerChatLogs([]byte(chatlog))
 	assert.Nil(t, err)
 	t.Log(logs)
+	assert.Len(t, logs, 11)
+	assert.EqualValues(t, "(aaa)", logs[6].Msg)
+	assert.EqualValues(t, `\n`, logs[5].Msg)
+	assert.EqualValues(t, "寒江蓑笠翁", logs[3].Name)
+	assert.EqualValues(t, "rollback passed", logs[9].Msg)
+}
+
+func TestParseServerChatLogsGrammar(t *testing.T) {
+	const chatlog = "[00:01:18]: [Join Announcement] Old Man: Fisher\r\n" +
+		"[00:01:57]: [Say] (KU_iJIpcpXi) Old Man: Fisher: hello: world [x] (y)\r\n" +
+		"[00:02:00]: [Say] (KU_abcdefgh) John Doe: hi\r\n" +
+		"[00:05:32]: [Death Announcement] John Doe was killed by Spider Warrior. He became a ghost!\r\n" +
+		"[00:06:00]: [Roll Announcement] (KU_abcdefgh) John Doe 7 (1-10)\r\n" +
+		"garbage line\r\n" +
+		"[00:07:00]: [Say] KU_abcdefgh John Doe hi\r\n"
+
+	logs, err := ParseServerChatLogs([]byte(chatlog))
+	assert.NotNil(t, err)
+	t.Log(err)
+	assert.Len(t, logs, 5)
+
+	assert.EqualValues(t, "Old Man: Fisher", logs[0].Name)
+	assert.EqualValues(t, "Old Man: Fisher", logs[1].Name)
+	assert.EqualValues(t, "hello: world [x] (y)", logs[1].Msg)
+	assert.EqualValues(t, "John Doe", logs[2].Name)
+	assert.EqualValues(t, "hi", logs[2].Msg)
+	assert.EqualValues(t, "John Doe", logs[3].Name)
+	assert.EqualValues(t, "John Doe", logs[4].Name)
+	assert.EqualValues(t, "KU_abcdefgh", logs[4].KleiId)
+
+	var syntaxErr *ChatLogSyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.EqualValues(t, 6, syntaxErr.Line)
+}
+
+func TestParseServerChatLogsUnknownMarker(t *testing.T) {
+	logs, err := ParseServerChatLogs([]byte("[00:05:32]: [Death Announcement] Вилсон был убит: Паук.\n"))
+	assert.Nil(t, err)
+	assert.Len(t, logs, 1)
+	assert.EqualValues(t, ChatTypeDeath, logs[0].Type)
+	assert.Empty(t, logs[0].Name)
+	assert.EqualValues(t, "Вилсон был убит: Паук.", logs[0].Msg)
+}
+
+func TestParsePlayerList(t *testing.T) {
+	const adminlist = "\xEF\xBB\xBF# admins\r\nKU_iJIpcpXi\r\n\r\nKU_abcdefgh\r\nKU_iJIpcpXi\r\n"
+	list, err := ParsePlayerList(AdminList, []byte(adminlist))
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"KU_iJIpcpXi", "KU_abcdefgh"}, list.IDs())
+	assert.EqualValues(t, "adminlist.txt", list.Kind.FileName())
+
+	assert.True(t, list.Contains("KU_abcdefgh"))
+	assert.True(t, list.Remove("KU_abcdefgh"))
+	assert.False(t, list.Remove("KU_abcdefgh"))
+	assert.Nil(t, list.Add("KU_12345678"))
+	assert.NotNil(t, list.Add("12345678"))
+
+	assert.EqualValues(t, "KU_iJIpcpXi\nKU_12345678\n", string(ToPlayerListTxt(list)))
+
+	_, err = ParsePlayerList(BlockList, []byte("KU_iJIpcpXi\nnot an id\n"))
+	assert.NotNil(t, err)
+	t.Log(err)
 }
@@ -0,0 +1,294 @@
+package dstparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ids of built-in world presets
+const (
+	PresetSurvivalTogether        = "SURVIVAL_TOGETHER"
+	PresetSurvivalTogetherClassic = "SURVIVAL_TOGETHER_CLASSIC"
+	PresetSurvivalDefaultPlus     = "SURVIVAL_DEFAULT_PLUS"
+	PresetCompleteDarkness        = "COMPLETE_DARKNESS"
+	PresetEndless                 = "ENDLESS"
+	PresetWilderness              = "WILDERNESS"
+	PresetDSTCave                 = "DST_CAVE"
+	PresetDSTCavePlus             = "DST_CAVE_PLUS"
+	PresetLavaArena               = "LAVAARENA"
+	PresetQuagmire                = "QUAGMIRE"
+)
+
+type levelPreset struct {
+	id        string
+	name      string
+	desc      string
+	location  string
+	playstyle string
+	// overrides differ from the default values of location
+	overrides map[string]any
+}
+
+var (
+	survivalRandomSetPieces = []string{
+		"Sculptures_2", "Sculptures_3", "Sculptures_4", "Sculptures_5",
+		"Chessy_1", "Chessy_2", "Chessy_3", "Chessy_4", "Chessy_5", "Chessy_6",
+		"Maxwell1", "Maxwell2", "Maxwell3", "Maxwell4", "Maxwell6", "Maxwell7",
+		"Warzone_1", "Warzone_2", "Warzone_3",
+	}
+	survivalRequiredSetPieces = []string{"Sculptures_1", "Maxwell5"}
+
+	levelPresets = []levelPreset{
+		{
+			id:        PresetSurvivalTogether,
+			name:      "Standard",
+			desc:      "The standard Don't Starve experience.",
+			location:  LocationForest,
+			playstyle: "survival",
+		},
+		{
+			id:        PresetSurvivalTogetherClassic,
+			name:      "Classic",
+			desc:      "The standard Don't Starve experience, without Reign of Giants content.",
+			location:  LocationForest,
+			playstyle: "survival",
+			overrides: map[string]any{
+				"task_set":         "classic",
+				"spring":           "noseason",
+				"summer":           "noseason",
+				"frograin":         "never",
+				"wildfires":        "never",
+				"bearger":          "never",
+				"goosemoose":       "never",
+				"dragonfly":        "never",
+				"deciduousmonster": "never",
+				"houndmound":       "never",
+				"buzzard":          "never",
+				"catcoon":          "never",
+				"moles":            "never",
+				"lightninggoat":    "never",
+				"rock_ice":         "never",
+				"cactus":           "never",
+			},
+		},
+		{
+			id:        PresetSurvivalDefaultPlus,
+			name:      "Plus",
+			desc:      "A harder start with more boons, spiders and fewer food resources.",
+			location:  LocationForest,
+			playstyle: "survival",
+			overrides: map[string]any{
+				"start_location": "plus",
+				"boons":          "often",
+				"spiders":        "often",
+				"berrybush":      "rare",
+				"carrot":         "rare",
+				"rabbits":        "rare",
+			},
+		},
+		{
+			id:        PresetCompleteDarkness,
+			name:      "Lights Out",
+			desc:      "A dark, dark world.",
+			location:  LocationForest,
+			playstyle: "survival",
+			overrides: map[string]any{
+				"start_location": "darkness",
+				"day":            "onlynight",
+			},
+		},
+		{
+			id:        PresetEndless,
+			name:      "Endless",
+			desc:      "A never-ending sandbox version of Don't Starve.\nAlways able to resurrect at the Florid Postern.",
+			location:  LocationForest,
+			playstyle: "endless",
+			overrides: map[string]any{
+				"basicresource_regrowth": "always",
+				"ghostsanitydrain":       "none",
+				"portalresurection":      "always",
+				"resettime":              "none",
+			},
+		},
+		{
+			id:        PresetWilderness,
+			name:      "Wilderness",
+			desc:      "It's out there, filled with danger!\nSpawn in a random location.\nWhen you die: pick a new survivor and try again.",
+			location:  LocationForest,
+			playstyle: "wilderness",
+			overrides: map[string]any{
+				"spawnmode":              "scatter",
+				"ghostenabled":           "none",
+				"basicresource_regrowth": "always",
+				"resettime":              "none",
+			},
+		},
+		{
+			id:       PresetDSTCave,
+			name:     "Caves",
+			desc:     "Delve into the caves... together!",
+			location: LocationCave,
+		},
+		{
+			id:       PresetDSTCavePlus,
+			name:     "Caves Plus",
+			desc:     "A harder cave with more boons, spiders and fewer food resources.",
+			location: LocationCave,
+			overrides: map[string]any{
+				"boons":        "often",
+				"cave_spiders": "often",
+				"berrybush":    "rare",
+			},
+		},
+		{
+			id:       PresetLavaArena,
+			name:     "The Forge",
+			desc:     "Battle your way through the Forge.",
+			location: LocationLavaArena,
+		},
+		{
+			id:       PresetQuagmire,
+			name:     "The Gorge",
+			desc:     "Cook your way through the Gorge.",
+			location: LocationQuagmire,
+		},
+	}
+)
+
+// Presets returns the ids of built-in world presets
+func Presets() []string {
+	ids := make([]string, 0, len(levelPresets))
+	for _, preset := range levelPresets {
+		ids = append(ids, preset.id)
+	}
+	return ids
+}
+
+// NewLevelDataOverrides returns the leveldataoverride of built-in preset as the game writes,
+// overrides of the location are fully populated with default values and sorted by key,
+// egs. NewLevelDataOverrides(PresetEndless)
+func NewLevelDataOverrides(preset string) (LevelDataOverrides, error) {
+	for _, p := range levelPresets {
+		if p.id == preset {
+			return p.levelDataOverrides(), nil
+		}
+	}
+	return LevelDataOverrides{}, fmt.Errorf("unknown world preset %s", preset)
+}
+
+func (p levelPreset) levelDataOverrides() LevelDataOverrides {
+	overrides := LevelDataOverrides{
+		Id:                    p.id,
+		Name:                  p.name,
+		Desc:                  p.desc,
+		Location:              p.location,
+		PlayStyle:             p.playstyle,
+		Version:               4,
+		MaxPlayerListPosition: 999,
+		SettingId:             p.id,
+		SettingName:           p.name,
+		SettingDesc:           p.desc,
+		WorldGenId:            p.id,
+		WorldGenName:          p.name,
+		WorldGenDesc:          p.desc,
+		RequiredPrefabs:       []string{"multiplayer_portal"},
+	}
+
+	switch p.location {
+	case LocationForest:
+		overrides.NumRandomSetPieces = 4
+		overrides.RandomSetPieces = append([]string(nil), survivalRandomSetPieces...)
+		overrides.RequiredSetPieces = append([]string(nil), survivalRequiredSetPieces...)
+	case LocationCave:
+		overrides.BackGroundNodeRange = []float64{0, 1}
+	case LocationLavaArena, LocationQuagmire:
+		overrides.RequiredPrefabs = nil
+		overrides.BackGroundNodeRange = []float64{0, 1}
+	}
+
+	for _, key := range OverrideKeys(p.location) {
+		value := key.DefaultFor(p.location)
+		if v, ok := p.overrides[key.Name]; ok {
+			value = v
+		}
+		overrides.Overrides = append(overrides.Overrides, LevelOverrideItem{Name: key.Name, Value: value})
+	}
+	sort.Slice(overrides.Overrides, func(i, j int) bool {
+		return overrides.Overrides[i].Name < overrides.Overrides[j].Name
+	})
+
+	return overrides
+}
+
+// baseline returns the value of override name in preset, or the default value of the location if preset has no such key
+func (l LevelDataOverrides) baseline(name string) (any, bool) {
+	for _, item := range l.Overrides {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	if key, ok := LookupOverrideKey(name); ok && key.AvailableIn(l.Location) {
+		return key.DefaultFor(l.Location), true
+	}
+	return nil, false
+}
+
+// Delta returns a copy of overrides which only keeps the overrides differ from preset, egs.
+//
+//	preset, _ := NewLevelDataOverrides(PresetSurvivalTogether)
+//	customized := overrides.Delta(preset)
+//
+// Keys unknown to preset are kept, other fields are kept as is.
+func (l LevelDataOverrides) Delta(preset LevelDataOverrides) LevelDataOverrides {
+	delta := l
+	delta.Overrides = nil
+	for _, item := range l.Overrides {
+		if value, ok := preset.baseline(item.Name); ok && reflect.DeepEqual(value, item.Value) {
+			continue
+		}
+		delta.Overrides = append(delta.Overrides, item)
+	}
+	return delta
+}
+
+// Expand fills sparse overrides back to a full leveldataoverride based on preset, which is the reverse of Delta.
+// Overrides are in the order of preset, followed by keys unknown to preset. Other fields are taken from sparse
+// if present in the parsed file, see Keys, so that false or 0 in sparse overrides the preset. If sparse is not
+// parsed, its fields are taken only if not zero, otherwise from preset.
+func (l LevelDataOverrides) Expand(preset LevelDataOverrides) LevelDataOverrides {
+	values := make(map[string]any, len(l.Overrides))
+	for _, item := range l.Overrides {
+		values[item.Name] = item.Value
+	}
+
+	expanded := preset
+	expanded.Overrides = nil
+	for _, item := range preset.Overrides {
+		if value, ok := values[item.Name]; ok {
+			item.Value = value
+			delete(values, item.Name)
+		}
+		expanded.Overrides = append(expanded.Overrides, item)
+	}
+	for _, item := range l.Overrides {
+		if _, ok := values[item.Name]; ok {
+			expanded.Overrides = append(expanded.Overrides, item)
+		}
+	}
+
+	// fields of sparse take precedence
+	sparse, full := reflect.ValueOf(l), reflect.ValueOf(&expanded).Elem()
+	for i := 0; i < sparse.NumField(); i++ {
+		field := sparse.Type().Field(i)
+		if field.Name == "Keys" || field.Name == "Overrides" {
+			continue
+		}
+		if !l.HasKey(levelDataKeyOf(field.Tag.Get("mapstructure"))) && sparse.Field(i).IsZero() {
+			continue
+		}
+		full.Field(i).Set(sparse.Field(i))
+	}
+
+	return expanded
+}
@@ -0,0 +1,132 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestNewLevelDataOverrides(t *testing.T) {
+	for _, preset := range Presets() {
+		overrides, err := NewLevelDataOverrides(preset)
+		assert.Nil(t, err)
+		assert.Equal(t, preset, overrides.Id)
+		assert.NotEmpty(t, overrides.Overrides, preset)
+		assert.Nil(t, overrides.Validate(), preset)
+	}
+
+	_, err := NewLevelDataOverrides("NOT_A_PRESET")
+	assert.NotNil(t, err)
+}
+
+func TestNewLevelDataOverridesEndless(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/leveldataoverride.master.lua")
+	assert.Nil(t, err)
+	expected, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+
+	overrides, err := NewLevelDataOverrides(PresetEndless)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, expected.Overrides, overrides.Overrides)
+	assert.Equal(t, expected.RandomSetPieces, overrides.RandomSetPieces)
+	assert.Equal(t, expected.RequiredSetPieces, overrides.RequiredSetPieces)
+	assert.Equal(t, expected.RequiredPrefabs, overrides.RequiredPrefabs)
+	assert.Equal(t, expected.NumRandomSetPieces, overrides.NumRandomSetPieces)
+	assert.Equal(t, expected.PlayStyle, overrides.PlayStyle)
+}
+
+func TestNewLevelDataOverridesCave(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/leveldataoverride.cave.lua")
+	assert.Nil(t, err)
+	expected, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+
+	overrides, err := NewLevelDataOverrides(PresetDSTCave)
+	assert.Nil(t, err)
+	assert.Equal(t, expected.BackGroundNodeRange, overrides.BackGroundNodeRange)
+
+	values := make(map[string]any)
+	for _, item := range overrides.Overrides {
+		values[item.Name] = item.Value
+	}
+	for _, item := range expected.Overrides {
+		if key, _ := LookupOverrideKey(item.Name); key.Category == OverrideWorldGen {
+			assert.Equal(t, item.Value, values[item.Name], item.Name)
+		}
+	}
+}
+
+func TestNewLevelDataOverridesLavaArena(t *testing.T) {
+	overrides, err := NewLevelDataOverrides(PresetLavaArena)
+	assert.Nil(t, err)
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "task_set", Value: "lavaarena_taskset"})
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "world_size", Value: "small"})
+	for _, item := range overrides.Overrides {
+		assert.NotEqual(t, "bearger", item.Name)
+	}
+}
+
+func TestLevelDataOverridesDelta(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/leveldataoverride.master.lua")
+	assert.Nil(t, err)
+	overrides, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+
+	endless, err := NewLevelDataOverrides(PresetEndless)
+	assert.Nil(t, err)
+	assert.Empty(t, overrides.Delta(endless).Overrides)
+
+	survival, err := NewLevelDataOverrides(PresetSurvivalTogether)
+	assert.Nil(t, err)
+	delta := overrides.Delta(survival)
+	assert.ElementsMatch(t, []LevelOverrideItem{
+		{Name: "basicresource_regrowth", Value: "always"},
+		{Name: "ghostsanitydrain", Value: "none"},
+		{Name: "portalresurection", Value: "always"},
+		{Name: "resettime", Value: "none"},
+	}, delta.Overrides)
+	assert.Equal(t, overrides.Id, delta.Id)
+
+	// keys unknown to preset are kept
+	overrides.Overrides = append(overrides.Overrides, LevelOverrideItem{Name: "my_mod_key", Value: "default"})
+	assert.Contains(t, overrides.Delta(endless).Overrides, LevelOverrideItem{Name: "my_mod_key", Value: "default"})
+}
+
+func TestLevelDataOverridesExpand(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/leveldataoverride.master.lua")
+	assert.Nil(t, err)
+	overrides, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+
+	survival, err := NewLevelDataOverrides(PresetSurvivalTogether)
+	assert.Nil(t, err)
+	expanded := overrides.Delta(survival).Expand(survival)
+	assert.ElementsMatch(t, overrides.Overrides, expanded.Overrides)
+	assert.Equal(t, overrides.Id, expanded.Id)
+	assert.Equal(t, overrides.Desc, expanded.Desc)
+
+	sparse := LevelDataOverrides{Overrides: []LevelOverrideItem{{Name: "day", Value: "longdusk"}, {Name: "my_mod_key", Value: true}}}
+	expanded = sparse.Expand(survival)
+	assert.Equal(t, survival.Id, expanded.Id)
+	assert.Equal(t, survival.RandomSetPieces, expanded.RandomSetPieces)
+	assert.Len(t, expanded.Overrides, len(survival.Overrides)+1)
+	assert.Contains(t, expanded.Overrides, LevelOverrideItem{Name: "day", Value: "longdusk"})
+	assert.Equal(t, LevelOverrideItem{Name: "my_mod_key", Value: true}, expanded.Overrides[len(expanded.Overrides)-1])
+
+	// zero values present in parsed sparse overrides take precedence
+	survival.HideMiniMap = true
+	sparse, err = ParseLevelDataOverrides([]byte(`return { hideminimap=false, max_playlist_position=6, numrandom_set_pieces=0, overrides={ day="longdusk" } }`))
+	assert.Nil(t, err)
+	expanded = sparse.Expand(survival)
+	assert.False(t, expanded.HideMiniMap)
+	assert.Zero(t, expanded.NumRandomSetPieces)
+	assert.EqualValues(t, 6, expanded.MaxPlayerListPosition)
+	assert.Equal(t, survival.Id, expanded.Id)
+	assert.Nil(t, expanded.Keys)
+
+	// zero values can not override if sparse is not parsed
+	sparse.Keys = nil
+	expanded = sparse.Expand(survival)
+	assert.True(t, expanded.HideMiniMap)
+	assert.Equal(t, survival.NumRandomSetPieces, expanded.NumRandomSetPieces)
+}
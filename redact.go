@@ -0,0 +1,78 @@
+package dstparser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RedactedMask replaces the secret values in redacted output
+const RedactedMask = "******"
+
+func redact(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+	return RedactedMask
+}
+
+// Redact returns a copy of network settings with cluster_password masked
+func (n ClusterNetwork) Redact() ClusterNetwork {
+	n.ClusterPassword = redact(n.ClusterPassword)
+	return n
+}
+
+// Redact returns a copy of shard settings with cluster_key masked
+func (s ClusterShard) Redact() ClusterShard {
+	s.ClusterKey = redact(s.ClusterKey)
+	return s
+}
+
+// Redact returns a copy of the config with all secret fields masked
+func (c ClusterConfig) Redact() ClusterConfig {
+	c.NetWork = c.NetWork.Redact()
+	c.Shard = c.Shard.Redact()
+	return c
+}
+
+// RevealedClusterConfig is ClusterConfig without redaction when printed or marshaled,
+// use it only when secrets are really needed.
+type RevealedClusterConfig ClusterConfig
+
+// Reveal opts in to print or marshal the config with secrets
+func (c ClusterConfig) Reveal() RevealedClusterConfig {
+	return RevealedClusterConfig(c)
+}
+
+// String returns the redacted representation of config
+func (c ClusterConfig) String() string {
+	return fmt.Sprintf("%+v", RevealedClusterConfig(c.Redact()))
+}
+
+// MarshalJSON marshals the redacted config
+func (c ClusterConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RevealedClusterConfig(c.Redact()))
+}
+
+// Redact returns a copy of the cluster with all secret fields masked
+func (c Cluster) Redact() Cluster {
+	c.Config = c.Config.Redact()
+	c.Token = c.Token.Redact()
+	return c
+}
+
+// RevealedCluster is Cluster without redaction when printed or marshaled,
+// use it only when secrets are really needed.
+type RevealedCluster struct {
+	Config RevealedClusterConfig
+	Token  RevealedClusterToken
+	Shards []Shard
+}
+
+// Reveal opts in to print or marshal the cluster with secrets
+func (c Cluster) Reveal() RevealedCluster {
+	return RevealedCluster{
+		Config: c.Config.Reveal(),
+		Token:  c.Token.Reveal(),
+		Shards: c.Shards,
+	}
+}
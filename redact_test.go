@@ -0,0 +1,53 @@
+package dstparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestClusterConfigRedact(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/cluster.ini")
+	assert.Nil(t, err)
+	config, err := ParseClusterInI(bytes)
+	assert.Nil(t, err)
+
+	redacted := config.Redact()
+	assert.EqualValues(t, RedactedMask, redacted.NetWork.ClusterPassword)
+	assert.EqualValues(t, RedactedMask, redacted.Shard.ClusterKey)
+	assert.EqualValues(t, config.NetWork.ClusterName, redacted.NetWork.ClusterName)
+	// the original one is untouched
+	assert.EqualValues(t, "supersecretkey", config.Shard.ClusterKey)
+
+	str := fmt.Sprintf("%v", config)
+	t.Log(str)
+	assert.False(t, strings.Contains(str, "supersecretkey"))
+
+	jsonData, err := json.Marshal(config)
+	assert.Nil(t, err)
+	t.Log(string(jsonData))
+	assert.False(t, strings.Contains(string(jsonData), "supersecretkey"))
+
+	revealed, err := json.Marshal(config.Reveal())
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(revealed), "supersecretkey"))
+}
+
+func TestClusterRedact(t *testing.T) {
+	cluster := Cluster{Config: ClusterConfig{Shard: ClusterShard{ClusterKey: "supersecretkey"}}}
+
+	str := fmt.Sprintf("%+v", cluster)
+	t.Log(str)
+	assert.False(t, strings.Contains(str, "supersecretkey"))
+
+	jsonData, err := json.Marshal(cluster)
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(jsonData), "supersecretkey"))
+
+	revealed, err := json.Marshal(cluster.Reveal())
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(revealed), "supersecretkey"))
+}
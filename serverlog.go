@@ -0,0 +1,193 @@
+package dstparser
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// ServerLogCategory is the category of line in server_log.txt
+type ServerLogCategory string
+
+const (
+	ServerLogStartup  ServerLogCategory = "startup"
+	ServerLogPlayer   ServerLogCategory = "player"
+	ServerLogChat     ServerLogCategory = "chat"
+	ServerLogShard    ServerLogCategory = "shard"
+	ServerLogWorldGen ServerLogCategory = "worldgen"
+	ServerLogMod      ServerLogCategory = "mod"
+	ServerLogSave     ServerLogCategory = "save"
+	ServerLogError    ServerLogCategory = "error"
+	// ServerLogRaw is the category of unknown lines
+	ServerLogRaw ServerLogCategory = "raw"
+)
+
+// ServerLogKind is the specific kind of line in ServerLogCategory
+type ServerLogKind string
+
+const (
+	ServerLogKindStartUp             ServerLogKind = "start_up"
+	ServerLogKindVersion             ServerLogKind = "version"
+	ServerLogKindCurrentTime         ServerLogKind = "current_time"
+	ServerLogKindShutdown            ServerLogKind = "shutdown"
+	ServerLogKindNewConnection       ServerLogKind = "new_connection"
+	ServerLogKindSteamAuthenticated  ServerLogKind = "steam_authenticated"
+	ServerLogKindClientAuthenticated ServerLogKind = "client_authenticated"
+	ServerLogKindSpawnRequest        ServerLogKind = "spawn_request"
+	ServerLogKindResumeUser          ServerLogKind = "resume_user"
+	ServerLogKindChatMessage         ServerLogKind = "chat_message"
+	ServerLogKindShardStartMaster    ServerLogKind = "shard_start_master"
+	ServerLogKindShardConnected      ServerLogKind = "shard_connected"
+	ServerLogKindShardDisconnected   ServerLogKind = "shard_disconnected"
+	ServerLogKindShardMessage        ServerLogKind = "shard_message"
+	ServerLogKindWorldGenProgress    ServerLogKind = "worldgen_progress"
+	ServerLogKindModLoading          ServerLogKind = "mod_loading"
+	ServerLogKindModMessage          ServerLogKind = "mod_message"
+	ServerLogKindModIndex            ServerLogKind = "mod_index"
+	ServerLogKindSerializeWorld      ServerLogKind = "serialize_world"
+	ServerLogKindSerializeUser       ServerLogKind = "serialize_user"
+	ServerLogKindLuaError            ServerLogKind = "lua_error"
+	ServerLogKindScriptError         ServerLogKind = "script_error"
+	ServerLogKindErrorMessage        ServerLogKind = "error_message"
+	ServerLogKindUnknown             ServerLogKind = "unknown"
+)
+
+// ServerLogRecord is a line in server_log.txt
+type ServerLogRecord struct {
+	// elapsed time since server started, lines without time use the offset of previous line
+	Offset time.Duration `mapstructure:"offset"`
+	// time in line like 00:01:18, empty for continuation lines such as stack traceback
+	Time     string            `mapstructure:"time"`
+	Category ServerLogCategory `mapstructure:"category"`
+	// specific kind of line in category, egs. client_authenticated
+	Kind ServerLogKind `mapstructure:"kind"`
+	// structured fields extracted from line, egs. klei_id, name
+	Fields map[string]string `mapstructure:"fields"`
+	// content after the time
+	Msg string `mapstructure:"msg"`
+	// the original line in log file
+	Raw string `mapstructure:"raw"`
+}
+
+type serverLogRule struct {
+	category ServerLogCategory
+	kind     ServerLogKind
+	pattern  *regexp.Regexp
+}
+
+var (
+	// logLinePattern matches [00:01:18]: content
+	logLinePattern = regexp.MustCompile(`^\[(\d+:\d{2}:\d{2})\]: ?(.*)$`)
+
+	serverLogRules = []serverLogRule{
+		{ServerLogStartup, ServerLogKindStartUp, regexp.MustCompile(`^Starting Up$`)},
+		{ServerLogStartup, ServerLogKindVersion, regexp.MustCompile(`^Version: (?P<version>\d+)`)},
+		{ServerLogStartup, ServerLogKindCurrentTime, regexp.MustCompile(`^Current time: (?P<time>.+)$`)},
+		{ServerLogStartup, ServerLogKindShutdown, regexp.MustCompile(`^Shutting down$`)},
+
+		{ServerLogPlayer, ServerLogKindNewConnection, regexp.MustCompile(`^New incoming connection (?P<address>\S+)(?: <(?P<guid>\d+)>)?`)},
+		{ServerLogPlayer, ServerLogKindSteamAuthenticated, regexp.MustCompile(`^\[Steam\] Authenticated host '(?P<platform_id>[^']+)'`)},
+		{ServerLogPlayer, ServerLogKindClientAuthenticated, regexp.MustCompile(`^Client authenticated: \((?P<klei_id>KU_[^)]+)\) (?P<name>.*)$`)},
+		{ServerLogPlayer, ServerLogKindSpawnRequest, regexp.MustCompile(`^Spawn request: (?P<prefab>\S+) from (?P<name>.*)$`)},
+		{ServerLogPlayer, ServerLogKindResumeUser, regexp.MustCompile(`^Resuming user: (?P<path>.*)$`)},
+		{ServerLogChat, ServerLogKindChatMessage, regexp.MustCompile(`^\[(?P<type>Say|Whisper|[A-Za-z ]*Announcement)\] (?P<body>.*)$`)},
+
+		{ServerLogShard, ServerLogKindShardStartMaster, regexp.MustCompile(`^\[Shard\] Starting master server`)},
+		{ServerLogShard, ServerLogKindShardConnected, regexp.MustCompile(`^\[Shard\] (?:Slave|Secondary shard) (?P<shard>[^(]*)\((?P<shard_id>\d+)\) connected: \[(?P<network>[^\]]*)\] (?P<address>.*)$`)},
+		{ServerLogShard, ServerLogKindShardDisconnected, regexp.MustCompile(`^\[Shard\] (?:Slave|Secondary shard) (?P<shard>[^(]*)\((?P<shard_id>\d+)\) disconnected`)},
+		{ServerLogShard, ServerLogKindShardMessage, regexp.MustCompile(`^\[Shard\] (?P<msg>.*)$`)},
+
+		{ServerLogWorldGen, ServerLogKindWorldGenProgress, regexp.MustCompile(`^(?P<msg>(?:Worldgen|WorldGen|WORLDGEN|Generating|Checking world).*)$`)},
+
+		{ServerLogMod, ServerLogKindModLoading, regexp.MustCompile(`^Loading mod: (?P<mod>\S+) \((?P<name>.*)\) Version:(?P<version>.*)$`)},
+		{ServerLogMod, ServerLogKindModMessage, regexp.MustCompile(`^Mod: (?P<mod>\S+) \((?P<name>.*?)\)\s+(?P<msg>.*)$`)},
+		{ServerLogMod, ServerLogKindModIndex, regexp.MustCompile(`^ModIndex: (?P<msg>.*)$`)},
+
+		{ServerLogSave, ServerLogKindSerializeWorld, regexp.MustCompile(`^Serializing world: (?P<path>.*)$`)},
+		{ServerLogSave, ServerLogKindSerializeUser, regexp.MustCompile(`^Serializing user: (?P<path>.*)$`)},
+
+		{ServerLogError, ServerLogKindLuaError, regexp.MustCompile(`^LUA ERROR stack traceback:`)},
+		{ServerLogError, ServerLogKindScriptError, regexp.MustCompile(`^\[string "(?P<file>[^"]+)"\]:(?P<line>\d+): (?P<msg>.*)$`)},
+		{ServerLogError, ServerLogKindErrorMessage, regexp.MustCompile(`^(?:ERROR|Error|error)\b:? ?(?P<msg>.*)$`)},
+	}
+)
+
+// ParseServerLog parses server_log.txt, returns a record for each line
+func ParseServerLog(content []byte) ([]ServerLogRecord, error) {
+	if len(content) == 0 {
+		return nil, nil
+	}
+	var records []ServerLogRecord
+	reader := NewServerLogReader(strings.NewReader(unsafe.String(unsafe.SliceData(content), len(content))))
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// ServerLogReader reads server_log.txt records from io.Reader line by line
+type ServerLogReader struct {
+	reader *bufio.Reader
+	offset time.Duration
+}
+
+// NewServerLogReader returns a ServerLogReader reading from r
+func NewServerLogReader(r io.Reader) *ServerLogReader {
+	return &ServerLogReader{reader: bufio.NewReader(r)}
+}
+
+// Next returns the next record, returns io.EOF if there is no more lines
+func (r *ServerLogReader) Next() (ServerLogRecord, error) {
+	for {
+		line, err := r.reader.ReadString('\n')
+		if len(line) == 0 && err != nil {
+			return ServerLogRecord{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		record := parseServerLogLine(line, r.offset)
+		r.offset = record.Offset
+		return record, nil
+	}
+}
+
+// parseServerLogLine parses a line, prevOffset is used for lines without time
+func parseServerLogLine(line string, prevOffset time.Duration) ServerLogRecord {
+	record := ServerLogRecord{Offset: prevOffset, Category: ServerLogRaw, Kind: ServerLogKindUnknown, Msg: line, Raw: line}
+
+	if match := logLinePattern.FindStringSubmatch(line); match != nil {
+		if offset, err := ParseLogOffset(match[1]); err == nil {
+			record.Time, record.Offset, record.Msg = match[1], offset, match[2]
+		}
+	}
+
+	for _, rule := range serverLogRules {
+		match := rule.pattern.FindStringSubmatch(record.Msg)
+		if match == nil {
+			continue
+		}
+		record.Category, record.Kind = rule.category, rule.kind
+		for i, name := range rule.pattern.SubexpNames() {
+			if len(name) == 0 || len(match[i]) == 0 {
+				continue
+			}
+			if record.Fields == nil {
+				record.Fields = make(map[string]string)
+			}
+			record.Fields[name] = strings.TrimSpace(match[i])
+		}
+		break
+	}
+
+	return record
+}
@@ -0,0 +1,43 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseServerLog(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/log/server_log.txt")
+	assert.Nil(t, err)
+	records, err := ParseServerLog(bytes)
+	assert.Nil(t, err)
+	assert.Len(t, records, 28)
+
+	kinds := make(map[ServerLogKind]ServerLogRecord)
+	for _, record := range records {
+		t.Log(record.Time, record.Category, record.Kind, record.Fields)
+		if _, ok := kinds[record.Kind]; !ok {
+			kinds[record.Kind] = record
+		}
+	}
+
+	assert.EqualValues(t, "592193", kinds[ServerLogKindVersion].Fields["version"])
+	assert.EqualValues(t, "KU_iJIpcpXi", kinds[ServerLogKindClientAuthenticated].Fields["klei_id"])
+	assert.EqualValues(t, "寒江蓑笠翁", kinds[ServerLogKindClientAuthenticated].Fields["name"])
+	assert.EqualValues(t, 71*time.Second, kinds[ServerLogKindClientAuthenticated].Offset)
+	assert.EqualValues(t, "76561198000000001", kinds[ServerLogKindSteamAuthenticated].Fields["platform_id"])
+	assert.EqualValues(t, "Caves", kinds[ServerLogKindShardConnected].Fields["shard"])
+	assert.EqualValues(t, "workshop-1185229307", kinds[ServerLogKindModLoading].Fields["mod"])
+	assert.EqualValues(t, ServerLogSave, kinds[ServerLogKindSerializeWorld].Category)
+	assert.EqualValues(t, "123", kinds[ServerLogKindScriptError].Fields["line"])
+	assert.EqualValues(t, ServerLogError, kinds[ServerLogKindLuaError].Category)
+	assert.EqualValues(t, "Join Announcement", kinds[ServerLogKindChatMessage].Fields["type"])
+
+	// unknown and continuation lines
+	assert.EqualValues(t, ServerLogRaw, records[0].Category)
+	traceback := records[22]
+	assert.EqualValues(t, ServerLogRaw, traceback.Category)
+	assert.Empty(t, traceback.Time)
+	assert.EqualValues(t, 9*time.Minute+12*time.Second, traceback.Offset)
+}
@@ -0,0 +1,278 @@
+package dstparser
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// reasons of session ending
+const (
+	SessionLeft     = "left"
+	SessionMigrated = "migrated"
+	SessionKicked   = "kicked"
+	SessionBanned   = "banned"
+	SessionShutdown = "shutdown"
+)
+
+// DefaultMigrationWindow is the default max interval between leaving a shard and joining another one,
+// which is considered as migration instead of leaving the cluster.
+const DefaultMigrationWindow = 30 * time.Second
+
+// PlayerSession represents a period of player staying in a shard
+type PlayerSession struct {
+	KleiID     string    `mapstructure:"klei_id"`
+	Name       string    `mapstructure:"name"`
+	PlatformID string    `mapstructure:"platform_id"`
+	Shard      string    `mapstructure:"shard"`
+	Joined     time.Time `mapstructure:"joined"`
+	// zero if player is still online
+	Left time.Time `mapstructure:"left"`
+	// why the session ended, empty if player is still online
+	Reason string `mapstructure:"reason"`
+}
+
+// Online reports whether the player is online at t
+func (s PlayerSession) Online(t time.Time) bool {
+	return !t.Before(s.Joined) && (s.Left.IsZero() || t.Before(s.Left))
+}
+
+// SessionTracker builds player sessions from server_log.txt and server_chat_log.txt of shards.
+// Records should be observed in chronological order across shards to detect migrations,
+// the announcements appear in both files are counted once.
+type SessionTracker struct {
+	window   time.Duration
+	starts   map[string]time.Time
+	clocks   map[string]*LogClock
+	ids      map[string]string
+	platform map[string]string
+	reasons  map[*PlayerSession]string
+	online   []*PlayerSession
+	closed   []*PlayerSession
+}
+
+// NewSessionTracker returns an empty SessionTracker
+func NewSessionTracker() *SessionTracker {
+	return &SessionTracker{
+		window:   DefaultMigrationWindow,
+		starts:   make(map[string]time.Time),
+		clocks:   make(map[string]*LogClock),
+		ids:      make(map[string]string),
+		platform: make(map[string]string),
+		reasons:  make(map[*PlayerSession]string),
+	}
+}
+
+// SetShardStart sets the session start time of shard, which is used to compute the absolute time of log lines,
+// lines are timed from zero time if not set.
+func (t *SessionTracker) SetShardStart(shard string, start time.Time) {
+	t.starts[shard] = start
+}
+
+// SetMigrationWindow sets the max interval between leaving a shard and joining another one,
+// which is considered as migration instead of leaving the cluster, DefaultMigrationWindow if not set.
+func (t *SessionTracker) SetMigrationWindow(window time.Duration) {
+	t.window = window
+}
+
+func (t *SessionTracker) time(shard, source string, offset time.Duration) time.Time {
+	key := shard + "/" + source
+	if t.clocks[key] == nil {
+		t.clocks[key] = NewLogClock(t.starts[shard])
+	}
+	return t.clocks[key].Time(offset)
+}
+
+// ObserveServerLog consumes a server_log.txt record of shard
+func (t *SessionTracker) ObserveServerLog(shard string, record ServerLogRecord) {
+	if len(record.Time) == 0 {
+		return
+	}
+	at := t.time(shard, "server_log", record.Offset)
+
+	switch record.Kind {
+	case ServerLogKindSteamAuthenticated:
+		t.platform[shard] = record.Fields["platform_id"]
+	case ServerLogKindClientAuthenticated:
+		name, kleiID := record.Fields["name"], record.Fields["klei_id"]
+		t.learn(name, kleiID)
+		session := t.join(shard, name, kleiID, at)
+		if len(t.platform[shard]) > 0 {
+			session.PlatformID = t.platform[shard]
+			delete(t.platform, shard)
+		}
+	case ServerLogKindChatMessage:
+		// announcements are also written in server_log.txt
+		var parser chatLogParser
+		if log, err := parser.parseLine(record.Raw); err == nil {
+			t.observeChat(shard, log, at)
+		}
+	case ServerLogKindShutdown:
+		for _, session := range append([]*PlayerSession(nil), t.online...) {
+			if session.Shard == shard {
+				t.leave(session, at, SessionShutdown)
+			}
+		}
+	}
+}
+
+// ObserveChat consumes a server_chat_log.txt event of shard
+func (t *SessionTracker) ObserveChat(shard string, event ChatEvent) {
+	log := event.Record()
+	t.observeChat(shard, log, t.time(shard, "chat_log", log.Offset))
+}
+
+func (t *SessionTracker) observeChat(shard string, log ChatLog, at time.Time) {
+	switch log.Type {
+	case ChatTypeJoin:
+		t.join(shard, log.Name, "", at)
+	case ChatTypeLeave:
+		if session := t.find(shard, log.Name, ""); session != nil {
+			reason := SessionLeft
+			if r, ok := t.reasons[session]; ok {
+				reason = r
+				delete(t.reasons, session)
+			}
+			t.leave(session, at, reason)
+		}
+	case ChatTypeKick, ChatTypeBan:
+		reason := SessionKicked
+		if log.Type == ChatTypeBan {
+			reason = SessionBanned
+		}
+		// the longest name wins if several names match, egs. "Bob Smith has been kicked" is not about Bob
+		var kicked []*PlayerSession
+		for _, session := range t.online {
+			if session.Shard != shard || !announces(log.Msg, session.Name) {
+				continue
+			}
+			if len(kicked) > 0 && len(session.Name) > len(kicked[0].Name) {
+				kicked = kicked[:0]
+			}
+			if len(kicked) == 0 || len(session.Name) == len(kicked[0].Name) {
+				kicked = append(kicked, session)
+			}
+		}
+		for _, session := range kicked {
+			t.reasons[session] = reason
+		}
+	default:
+		if len(log.KleiId) > 0 && len(log.Name) > 0 {
+			t.learn(log.Name, log.KleiId)
+			if session := t.find(shard, log.Name, log.KleiId); session != nil && len(session.KleiID) == 0 {
+				session.KleiID = log.KleiId
+			}
+		}
+	}
+}
+
+// announces reports whether msg of kick or ban announcement is about player name, the name should be followed
+// by a word boundary, egs. "Bob has been kicked" is not about Bobby.
+func announces(msg, name string) bool {
+	if len(name) == 0 || !strings.HasPrefix(msg, name) {
+		return false
+	}
+	if len(msg) == len(name) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(msg[len(name):])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// learn remembers the klei id of player name, the name is forgotten if it is shared by different players
+func (t *SessionTracker) learn(name, kleiID string) {
+	if id, ok := t.ids[name]; ok && id != kleiID {
+		t.ids[name] = ""
+		return
+	}
+	t.ids[name] = kleiID
+}
+
+// samePlayer reports whether session belongs to the player, klei id is compared if both are known,
+// otherwise the name is compared.
+func samePlayer(session *PlayerSession, name, kleiID string) bool {
+	if len(kleiID) > 0 && len(session.KleiID) > 0 {
+		return session.KleiID == kleiID
+	}
+	return session.Name == name
+}
+
+// find returns the online session of player in shard, kleiID is empty if unknown
+func (t *SessionTracker) find(shard, name, kleiID string) *PlayerSession {
+	for _, session := range t.online {
+		if session.Shard == shard && samePlayer(session, name, kleiID) {
+			return session
+		}
+	}
+	return nil
+}
+
+// join opens a session if player is not online in shard, and marks the session in other shard as migrated,
+// kleiID is empty if unknown.
+func (t *SessionTracker) join(shard, name, kleiID string, at time.Time) *PlayerSession {
+	if len(kleiID) == 0 {
+		kleiID = t.ids[name]
+	}
+	if session := t.find(shard, name, kleiID); session != nil {
+		if len(session.KleiID) == 0 {
+			session.KleiID = kleiID
+		}
+		return session
+	}
+
+	session := &PlayerSession{KleiID: kleiID, Name: name, Shard: shard, Joined: at}
+	for _, other := range t.online {
+		if other.Shard != shard && samePlayer(other, name, kleiID) {
+			t.leave(other, at, SessionMigrated)
+			break
+		}
+	}
+	for i := len(t.closed) - 1; i >= 0; i-- {
+		other := t.closed[i]
+		if other.Shard != shard && samePlayer(other, name, kleiID) && other.Reason == SessionLeft && at.Sub(other.Left) <= t.window {
+			other.Reason = SessionMigrated
+			break
+		}
+	}
+	t.online = append(t.online, session)
+	return session
+}
+
+func (t *SessionTracker) leave(session *PlayerSession, at time.Time, reason string) {
+	session.Left, session.Reason = at, reason
+	for i, s := range t.online {
+		if s == session {
+			t.online = append(t.online[:i], t.online[i+1:]...)
+			break
+		}
+	}
+	t.closed = append(t.closed, session)
+}
+
+// Sessions returns all sessions ordered by joined time, including the online ones
+func (t *SessionTracker) Sessions() []PlayerSession {
+	sessions := make([]PlayerSession, 0, len(t.closed)+len(t.online))
+	for _, session := range t.closed {
+		sessions = append(sessions, *session)
+	}
+	for _, session := range t.online {
+		sessions = append(sessions, *session)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Joined.Before(sessions[j].Joined)
+	})
+	return sessions
+}
+
+// OnlineAt returns the sessions of players online at the time
+func (t *SessionTracker) OnlineAt(at time.Time) []PlayerSession {
+	var sessions []PlayerSession
+	for _, session := range t.Sessions() {
+		if session.Online(at) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
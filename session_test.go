@@ -0,0 +1,137 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSessionTrackerServerLog(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/log/server_log.txt")
+	assert.Nil(t, err)
+	records, err := ParseServerLog(bytes)
+	assert.Nil(t, err)
+
+	start := time.Date(2024, 4, 6, 12, 30, 0, 0, time.UTC)
+	tracker := NewSessionTracker()
+	tracker.SetShardStart("Master", start)
+	for _, record := range records {
+		tracker.ObserveServerLog("Master", record)
+	}
+
+	sessions := tracker.Sessions()
+	assert.Len(t, sessions, 1)
+	session := sessions[0]
+	t.Logf("%+v", session)
+	assert.EqualValues(t, "KU_iJIpcpXi", session.KleiID)
+	assert.EqualValues(t, "寒江蓑笠翁", session.Name)
+	assert.EqualValues(t, "76561198000000001", session.PlatformID)
+	assert.EqualValues(t, start.Add(71*time.Second), session.Joined)
+	assert.EqualValues(t, start.Add(9*time.Minute+13*time.Second), session.Left)
+	assert.EqualValues(t, SessionLeft, session.Reason)
+
+	assert.Len(t, tracker.OnlineAt(start.Add(5*time.Minute)), 1)
+	assert.Len(t, tracker.OnlineAt(start.Add(10*time.Minute)), 0)
+}
+
+func TestSessionTrackerMigration(t *testing.T) {
+	master, err := ParseServerChatEvents([]byte(`[00:01:00]: [Join Announcement] Wilson
+[00:01:10]: [Say] (KU_wilson1) Wilson: going down
+[00:02:00]: [Leave Announcement] Wilson
+[00:03:00]: [Join Announcement] Willow`))
+	assert.Nil(t, err)
+	caves, err := ParseServerChatEvents([]byte(`[00:02:05]: [Join Announcement] Wilson
+[00:05:00]: [Leave Announcement] Wilson`))
+	assert.Nil(t, err)
+
+	tracker := NewSessionTracker()
+	for _, event := range master[:3] {
+		tracker.ObserveChat("Master", event)
+	}
+	tracker.ObserveChat("Caves", caves[0])
+	tracker.ObserveChat("Master", master[3])
+	tracker.ObserveChat("Caves", caves[1])
+
+	sessions := tracker.Sessions()
+	assert.Len(t, sessions, 3)
+	assert.EqualValues(t, "Master", sessions[0].Shard)
+	assert.EqualValues(t, SessionMigrated, sessions[0].Reason)
+	assert.EqualValues(t, "KU_wilson1", sessions[0].KleiID)
+	assert.EqualValues(t, "Caves", sessions[1].Shard)
+	assert.EqualValues(t, "KU_wilson1", sessions[1].KleiID)
+	assert.EqualValues(t, SessionLeft, sessions[1].Reason)
+	assert.EqualValues(t, "Willow", sessions[2].Name)
+	assert.True(t, sessions[2].Left.IsZero())
+}
+
+func TestSessionTrackerKick(t *testing.T) {
+	events, err := ParseServerChatEvents([]byte(`[00:01:00]: [Join Announcement] Bob
+[00:01:05]: [Join Announcement] Bobby
+[00:01:10]: [Join Announcement] Bob Smith
+[00:02:00]: [Kick Announcement] Bobby has been kicked from the game.
+[00:02:00]: [Leave Announcement] Bobby
+[00:03:00]: [Ban Announcement] Bob Smith has been banned from the game.
+[00:03:00]: [Leave Announcement] Bob Smith
+[00:04:00]: [Leave Announcement] Bob`))
+	assert.Nil(t, err)
+
+	tracker := NewSessionTracker()
+	for _, event := range events {
+		tracker.ObserveChat("Master", event)
+	}
+
+	reasons := make(map[string]string)
+	for _, session := range tracker.Sessions() {
+		reasons[session.Name] = session.Reason
+	}
+	assert.Equal(t, map[string]string{"Bob": SessionLeft, "Bobby": SessionKicked, "Bob Smith": SessionBanned}, reasons)
+}
+
+func TestSessionTrackerSameName(t *testing.T) {
+	master, err := ParseServerLog([]byte(`[00:01:00]: Client authenticated: (KU_bob1) Bob
+[00:01:02]: [Join Announcement] Bob
+[00:02:00]: Client authenticated: (KU_bob2) Bob
+[00:02:02]: [Join Announcement] Bob
+`))
+	assert.Nil(t, err)
+	caves, err := ParseServerLog([]byte(`[00:03:00]: Client authenticated: (KU_bob2) Bob
+[00:03:02]: [Join Announcement] Bob
+`))
+	assert.Nil(t, err)
+
+	tracker := NewSessionTracker()
+	for _, record := range master {
+		tracker.ObserveServerLog("Master", record)
+	}
+	for _, record := range caves {
+		tracker.ObserveServerLog("Caves", record)
+	}
+
+	sessions := tracker.Sessions()
+	assert.Len(t, sessions, 3)
+	assert.EqualValues(t, "KU_bob1", sessions[0].KleiID)
+	assert.True(t, sessions[0].Left.IsZero())
+	assert.EqualValues(t, "KU_bob2", sessions[1].KleiID)
+	assert.EqualValues(t, SessionMigrated, sessions[1].Reason)
+	assert.EqualValues(t, "KU_bob2", sessions[2].KleiID)
+	assert.EqualValues(t, "Caves", sessions[2].Shard)
+}
+
+func TestSessionTrackerMigrationWindow(t *testing.T) {
+	master, err := ParseServerChatEvents([]byte(`[00:01:00]: [Join Announcement] Wilson
+[00:02:00]: [Leave Announcement] Wilson`))
+	assert.Nil(t, err)
+	caves, err := ParseServerChatEvents([]byte(`[00:02:05]: [Join Announcement] Wilson`))
+	assert.Nil(t, err)
+
+	for window, reason := range map[time.Duration]string{DefaultMigrationWindow: SessionMigrated, time.Second: SessionLeft} {
+		tracker := NewSessionTracker()
+		tracker.SetMigrationWindow(window)
+		for _, event := range master {
+			tracker.ObserveChat("Master", event)
+		}
+		tracker.ObserveChat("Caves", caves[0])
+		assert.EqualValues(t, reason, tracker.Sessions()[0].Reason, window)
+	}
+}
@@ -0,0 +1,140 @@
+package dstparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ShardIssueKind is the kind of inconsistency between leveldataoverride of shards
+type ShardIssueKind string
+
+const (
+	// ShardMismatch means a setting shared by the whole cluster differs between shards
+	ShardMismatch ShardIssueKind = "mismatch"
+	// ShardMisplaced means a key is not available in the location of shard, egs. autumn in caves
+	ShardMisplaced ShardIssueKind = "misplaced"
+)
+
+// ShardIssue is an inconsistency found in leveldataoverride of shards
+type ShardIssue struct {
+	Kind ShardIssueKind `mapstructure:"kind"`
+	// override key
+	Key string `mapstructure:"key"`
+	// names of shards involved, only one shard if misplaced
+	Shards []string `mapstructure:"shards"`
+	// values of key in Shards, in the same order
+	Values []any `mapstructure:"values"`
+	// location of shard if misplaced
+	Location string `mapstructure:"location"`
+}
+
+// String returns the issue in one line, egs. day: Master="longdusk", Caves="default"
+func (i ShardIssue) String() string {
+	if i.Kind == ShardMisplaced {
+		return fmt.Sprintf("%s: %s=%s is not available in %s", i.Shards[0], i.Key, formatLevelDataValue(i.Values[0]), i.Location)
+	}
+	values := make([]string, 0, len(i.Shards))
+	for j, shard := range i.Shards {
+		values = append(values, shard+"="+formatLevelDataValue(i.Values[j]))
+	}
+	return fmt.Sprintf("%s: %s", i.Key, strings.Join(values, ", "))
+}
+
+// settings groups which take effect on the whole cluster, caves follow seasons and day of master,
+// events and survivor rules are expected to be the same wherever players go.
+var sharedOverrideGroups = map[string]struct{}{
+	"global":    {},
+	"events":    {},
+	"survivors": {},
+}
+
+// keys out of sharedOverrideGroups which also take effect on the whole cluster
+var sharedOverrideKeys = map[string]struct{}{
+	"season_start": {},
+}
+
+func isSharedOverrideKey(key OverrideKey) bool {
+	if _, ok := sharedOverrideKeys[key.Name]; ok {
+		return true
+	}
+	_, ok := sharedOverrideGroups[key.Group]
+	return key.Category == OverrideSettings && ok
+}
+
+// CheckShardConsistency checks leveldataoverride of shards against each other, reports cluster-wide settings
+// which differ between shards in the order of override key catalog, followed by keys not available in
+// the location of shard, egs. forest-only keys in caves, in the order of shards.
+// Keys missing in a shard are taken as the default value of its location, shards without leveldataoverride
+// are skipped, and location check is skipped for locations unknown to the catalog. A key reported as misplaced
+// in a shard is not compared with other shards.
+func CheckShardConsistency(shards []Shard) []ShardIssue {
+	var checked []Shard
+	for _, shard := range shards {
+		if len(shard.LevelData.Location) > 0 || len(shard.LevelData.Overrides) > 0 {
+			checked = append(checked, shard)
+		}
+	}
+
+	values := make([]map[string]any, len(checked))
+	for i, shard := range checked {
+		values[i] = make(map[string]any, len(shard.LevelData.Overrides))
+		for _, item := range shard.LevelData.Overrides {
+			values[i][item.Name] = item.Value
+		}
+	}
+
+	var issues []ShardIssue
+	for _, key := range overrideCatalog {
+		if !isSharedOverrideKey(key) {
+			continue
+		}
+		issue := ShardIssue{Kind: ShardMismatch, Key: key.Name}
+		for i, shard := range checked {
+			location := shard.LevelData.Location
+			value, ok := values[i][key.Name]
+			switch {
+			case ok && isKnownLocation(location) && !key.AvailableIn(location):
+				// reported as misplaced
+				continue
+			case !ok && !key.AvailableIn(location):
+				continue
+			case !ok:
+				value = key.DefaultFor(location)
+			}
+			issue.Shards = append(issue.Shards, shard.Name)
+			issue.Values = append(issue.Values, value)
+		}
+		for _, value := range issue.Values {
+			if !reflect.DeepEqual(value, issue.Values[0]) {
+				issues = append(issues, issue)
+				break
+			}
+		}
+	}
+
+	for _, shard := range checked {
+		location := shard.LevelData.Location
+		if !isKnownLocation(location) {
+			continue
+		}
+		for _, item := range shard.LevelData.Overrides {
+			if key, ok := LookupOverrideKey(item.Name); ok && !key.AvailableIn(location) {
+				issues = append(issues, ShardIssue{
+					Kind:     ShardMisplaced,
+					Key:      item.Name,
+					Shards:   []string{shard.Name},
+					Values:   []any{item.Value},
+					Location: location,
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// CheckConsistency checks leveldataoverride of all shards in cluster, see CheckShardConsistency
+func (c Cluster) CheckConsistency() []ShardIssue {
+	return CheckShardConsistency(c.Shards)
+}
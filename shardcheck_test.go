@@ -0,0 +1,98 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func loadShards(t *testing.T) []Shard {
+	var shards []Shard
+	for _, shard := range []struct{ name, file string }{
+		{"Master", "testdata/cluster/leveldataoverride.master.lua"},
+		{"Caves", "testdata/cluster/leveldataoverride.cave.lua"},
+	} {
+		bytes, err := os.ReadFile(shard.file)
+		assert.Nil(t, err)
+		levelData, err := ParseLevelDataOverrides(bytes)
+		assert.Nil(t, err)
+		shards = append(shards, Shard{Name: shard.name, LevelData: levelData})
+	}
+	return shards
+}
+
+func setOverride(levelData *LevelDataOverrides, name string, value any) {
+	for i, item := range levelData.Overrides {
+		if item.Name == name {
+			levelData.Overrides[i].Value = value
+			return
+		}
+	}
+	levelData.Overrides = append(levelData.Overrides, LevelOverrideItem{Name: name, Value: value})
+}
+
+func TestCheckShardConsistency(t *testing.T) {
+	shards := loadShards(t)
+	assert.Empty(t, CheckShardConsistency(shards))
+
+	setOverride(&shards[0].LevelData, "day", "longdusk")
+	setOverride(&shards[1].LevelData, "specialevent", "winters_feast")
+	setOverride(&shards[1].LevelData, "beefaloheat", "often")
+	setOverride(&shards[1].LevelData, "autumn", "longseason")
+	setOverride(&shards[1].LevelData, "beefalo", "often")
+	// world specific settings are allowed to differ
+	setOverride(&shards[1].LevelData, "regrowth", "fast")
+
+	issues := CheckShardConsistency(shards)
+	for _, issue := range issues {
+		t.Log(issue)
+	}
+	assert.Equal(t, []ShardIssue{
+		{Kind: ShardMismatch, Key: "specialevent", Shards: []string{"Master", "Caves"}, Values: []any{"default", "winters_feast"}},
+		{Kind: ShardMismatch, Key: "day", Shards: []string{"Master", "Caves"}, Values: []any{"longdusk", "default"}},
+		{Kind: ShardMismatch, Key: "beefaloheat", Shards: []string{"Master", "Caves"}, Values: []any{"default", "often"}},
+		{Kind: ShardMisplaced, Key: "autumn", Shards: []string{"Caves"}, Values: []any{"longseason"}, Location: LocationCave},
+		{Kind: ShardMisplaced, Key: "beefalo", Shards: []string{"Caves"}, Values: []any{"often"}, Location: LocationCave},
+	}, issues)
+	assert.Equal(t, `day: Master="longdusk", Caves="default"`, issues[1].String())
+	assert.Equal(t, `Caves: autumn="longseason" is not available in cave`, issues[3].String())
+}
+
+func TestCheckShardConsistencyMisplacedShared(t *testing.T) {
+	shards := loadShards(t)
+	// shared keys only available in forest are reported once as misplaced
+	setOverride(&shards[1].LevelData, "autumn", "longseason")
+	setOverride(&shards[1].LevelData, "winter", "shortseason")
+
+	issues := CheckShardConsistency(shards)
+	assert.Len(t, issues, 2)
+	for _, issue := range issues {
+		assert.Equal(t, ShardMisplaced, issue.Kind, issue.String())
+	}
+}
+
+func TestCheckShardConsistencyMissingKeys(t *testing.T) {
+	master, err := NewLevelDataOverrides(PresetSurvivalTogether)
+	assert.Nil(t, err)
+	caves, err := NewLevelDataOverrides(PresetDSTCave)
+	assert.Nil(t, err)
+	shards := []Shard{{Name: "Master", LevelData: master}, {Name: "Caves", LevelData: caves}}
+	shards = append(shards, Shard{Name: "Caves2", LevelData: LevelDataOverrides{
+		Location:  LocationCave,
+		Overrides: []LevelOverrideItem{{Name: "krampus", Value: "never"}},
+	}})
+	// shards without leveldataoverride are skipped
+	shards = append(shards, Shard{Name: "Empty"})
+
+	issues := CheckShardConsistency(shards)
+	assert.Equal(t, []ShardIssue{
+		{Kind: ShardMismatch, Key: "krampus", Shards: []string{"Master", "Caves", "Caves2"}, Values: []any{"default", "default", "never"}},
+	}, issues)
+
+	// location check is skipped for worlds of mods
+	cluster := Cluster{Shards: []Shard{{Name: "Island", LevelData: LevelDataOverrides{
+		Location:  "shipwrecked",
+		Overrides: []LevelOverrideItem{{Name: "autumn", Value: "default"}},
+	}}}}
+	assert.Empty(t, cluster.CheckConsistency())
+}
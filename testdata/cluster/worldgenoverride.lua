@@ -0,0 +1,39 @@
+return {
+    override_enabled = true,
+    preset = "SURVIVAL_TOGETHER", -- "SURVIVAL_TOGETHER", "SURVIVAL_TOGETHER_CLASSIC", "SURVIVAL_DEFAULT_PLUS", "COMPLETE_DARKNESS"
+
+    unprepared = {
+        berrybush = "rare",
+        cactus = "default",
+        carrot = "default",
+        mushroom = "often",
+    },
+
+    misc = {
+        autumn = "longseason",
+        boons = "default",
+        day = "longdusk",
+        season_start = "default",
+        specialevent = "default",
+        world_size = "huge",
+    },
+
+    animals = {
+        beefalo = "often",
+        penguins = "never",
+        rabbits = "default",
+    },
+
+    monsters = {
+        deerclops = "rare",
+        hounds = "never",
+    },
+
+    resources = {
+        flint = "default",
+        grass = "often",
+        trees = "default",
+    },
+
+    spawnmode = "scatter",
+}
@@ -0,0 +1,24 @@
+return {
+  desc="A forest written without playstyle and set pieces.",
+  hideminimap=false,
+  id="SURVIVAL_TOGETHER",
+  location="forest",
+  max_playlist_position=999,
+  min_playlist_position=0,
+  name="Standard",
+  numrandom_set_pieces=4,
+  override_level_string=false,
+  overrides={
+    day="longdusk",
+    task_set="default"
+  },
+  required_prefabs={ "multiplayer_portal" },
+  settings_desc="",
+  settings_id="SURVIVAL_TOGETHER",
+  settings_name="Standard",
+  substitutes={  },
+  version=4,
+  worldgen_desc="",
+  worldgen_id="SURVIVAL_TOGETHER",
+  worldgen_name="Standard"
+}
@@ -0,0 +1,34 @@
+return {
+  custom={
+    [false]=0,
+    [true]=1,
+    [1.5]="half",
+    [2]="two",
+    ["1"]="one",
+    ["and"]=2,
+    plain=3
+  },
+  desc="Keys which can not be written as identifiers.",
+  hideminimap=false,
+  id="KEYWORDS",
+  location="keywords",
+  max_playlist_position=999,
+  min_playlist_position=0,
+  name="Keywords",
+  numrandom_set_pieces=0,
+  override_level_string=false,
+  overrides={
+    ["end"]="x",
+    ["nil"]=true,
+    ["repeat"]="default"
+  },
+  required_prefabs={ "multiplayer_portal" },
+  settings_desc="",
+  settings_id="KEYWORDS",
+  settings_name="Keywords",
+  substitutes={  },
+  version=4,
+  worldgen_desc="",
+  worldgen_id="KEYWORDS",
+  worldgen_name="Keywords"
+}
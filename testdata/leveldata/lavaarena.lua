@@ -0,0 +1,35 @@
+return {
+  background_node_range={ 0, 1 },
+  desc="Battle your way through the Forge.",
+  hideminimap=false,
+  id="LAVAARENA",
+  location="lavaarena",
+  max_playlist_position=999,
+  min_playlist_position=0,
+  name="The Forge",
+  numrandom_set_pieces=0,
+  override_level_string=false,
+  overrides={
+    boons="never",
+    keep_disconnected_tiles=true,
+    layout_mode="RestrictNodesByKey",
+    poi="never",
+    protected="never",
+    roads="never",
+    season_start="default",
+    start_location="lavaarena",
+    task_set="lavaarena_taskset",
+    touchstone="never",
+    traps="never",
+    world_size="small"
+  },
+  required_prefabs={  },
+  settings_desc="Battle your way through the Forge.",
+  settings_id="LAVAARENA",
+  settings_name="The Forge",
+  substitutes={  },
+  version=4,
+  worldgen_desc="Battle your way through the Forge.",
+  worldgen_id="LAVAARENA",
+  worldgen_name="The Forge"
+}
@@ -0,0 +1,55 @@
+return {
+  desc="Forest with more set pieces and tasks from world-gen mods.",
+  hideminimap=false,
+  id="MODDED_FOREST",
+  location="forest",
+  max_playlist_position=999,
+  min_playlist_position=0,
+  name="Modded Forest",
+  numrandom_set_pieces=4,
+  numrandom_set_pieces_ocean=2,
+  ordered_story_setpieces={ "TeleportatoRingLayout", "TeleportatoBoxLayout", "AdventurePortalLayout" },
+  override_level_string=false,
+  overrides={
+    day="default",
+    extra_tasks={ "Mole Colony Deciduous", "Mole Colony Rocks" },
+    regrowth="fast",
+    task_weights={
+      ["Befriend the pigs"]=2,
+      ["Make a pick"]=1
+    }
+  },
+  playstyle="survival",
+  random_set_pieces={
+    "Sculptures_2",
+    "Chessy_1"
+  },
+  required_prefabs={ "multiplayer_portal" },
+  required_setpieces={
+    "Sculptures_1",
+    "Maxwell5"
+  },
+  set_pieces={
+    ResurrectionStone={
+      count=2,
+      tasks={ "Make a pick", "Dig that rock", "Great Plains", "Squeltch" }
+    },
+    WormholeGrass={
+      count=8,
+      tasks={ "Make a pick", "Dig that rock" }
+    }
+  },
+  settings_desc="Forest with more set pieces and tasks from world-gen mods.",
+  settings_id="MODDED_FOREST",
+  settings_name="Modded Forest",
+  substitutes={  },
+  valid_start_tasks={ "Make a pick" },
+  version=4,
+  weights={
+    [1]=0.5,
+    [3]=1.5
+  },
+  worldgen_desc="Forest with more set pieces and tasks from world-gen mods.",
+  worldgen_id="MODDED_FOREST",
+  worldgen_name="Modded Forest"
+}
@@ -0,0 +1,35 @@
+return {
+  background_node_range={ 0, 1 },
+  desc="Cook your way through the Gorge.",
+  hideminimap=false,
+  id="QUAGMIRE",
+  location="quagmire",
+  max_playlist_position=999,
+  min_playlist_position=0,
+  name="The Gorge",
+  numrandom_set_pieces=0,
+  override_level_string=false,
+  overrides={
+    boons="never",
+    keep_disconnected_tiles=true,
+    layout_mode="RestrictNodesByKey",
+    poi="never",
+    protected="never",
+    roads="never",
+    season_start="default",
+    start_location="quagmire_startlocation",
+    task_set="quagmire_taskset",
+    touchstone="never",
+    traps="never",
+    world_size="small"
+  },
+  required_prefabs={  },
+  settings_desc="Cook your way through the Gorge.",
+  settings_id="QUAGMIRE",
+  settings_name="The Gorge",
+  substitutes={  },
+  version=4,
+  worldgen_desc="Cook your way through the Gorge.",
+  worldgen_id="QUAGMIRE",
+  worldgen_name="The Gorge"
+}
@@ -0,0 +1,30 @@
+return {
+  desc="Tropical islands added by a \"world gen\" mod.\
+Path: C:\\Games\\DST\tTabbed",
+  hideminimap=true,
+  id="SHIPWRECKED",
+  location="shipwrecked",
+  max_playlist_position=999,
+  min_playlist_position=0,
+  name="Shipwrecked",
+  numrandom_set_pieces=2,
+  override_level_string=true,
+  overrides={
+    day="longdusk",
+    island_quantity="often",
+    primary_land_size=1.5,
+    task_set="shipwrecked",
+    volcano=true,
+    ["world-seed"]="a b c"
+  },
+  random_set_pieces={ "Shipwrecked_1", "Shipwrecked_2" },
+  required_prefabs={ "multiplayer_portal", "volcano" },
+  settings_desc="Tropical islands",
+  settings_id="SHIPWRECKED",
+  settings_name="Shipwrecked",
+  substitutes={ "palmtree" },
+  version=4.5,
+  worldgen_desc="",
+  worldgen_id="SHIPWRECKED",
+  worldgen_name="Shipwrecked"
+}
@@ -0,0 +1,159 @@
+package dstparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// ClusterTokenPrefix is the prefix of all cluster tokens generated by klei account
+	ClusterTokenPrefix = "pds-"
+	// ClusterTokenSeparator separates the segments of cluster token
+	ClusterTokenSeparator = "^"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ClusterToken represents cluster_token.txt, the token looks like pds-g^KU_xxxxxxxx^xxxxxxxxxxxx=
+type ClusterToken struct {
+	// token type, egs. pds-g
+	Prefix string
+	// klei id of the token owner
+	KleiId string
+	// secret part of the token
+	Secret string
+}
+
+// ParseClusterToken parses the content of cluster_token.txt, the BOM and surrounding whitespaces are ignored,
+// use ValidateClusterTokenTxt to check whether the file can be accepted by server as it is.
+func ParseClusterToken(data []byte) (ClusterToken, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	content := strings.TrimSpace(string(data))
+	if len(content) == 0 {
+		return ClusterToken{}, errors.New("empty cluster token")
+	}
+
+	segments := strings.Split(content, ClusterTokenSeparator)
+	if len(segments) != 3 {
+		return ClusterToken{}, fmt.Errorf("cluster token should have 3 segments separated by %q, got %d", ClusterTokenSeparator, len(segments))
+	}
+
+	token := ClusterToken{Prefix: segments[0], KleiId: segments[1], Secret: segments[2]}
+	if err := token.Validate(); err != nil {
+		return ClusterToken{}, err
+	}
+	return token, nil
+}
+
+// ValidateClusterTokenTxt checks the raw content of cluster_token.txt, it reports BOM, whitespaces
+// and line breaks which make the server refuse the token, as well as the malformed token.
+func ValidateClusterTokenTxt(data []byte) error {
+	if bytes.HasPrefix(data, utf8BOM) {
+		return errors.New("cluster token file starts with UTF-8 BOM")
+	}
+	if !utf8.Valid(data) {
+		return errors.New("cluster token file is not valid UTF-8")
+	}
+	content := string(data)
+	if strings.TrimSpace(content) != content {
+		return errors.New("cluster token file has leading or trailing whitespaces")
+	}
+	if strings.ContainsAny(content, "\r\n") {
+		return errors.New("cluster token file has multiple lines")
+	}
+	_, err := ParseClusterToken(data)
+	return err
+}
+
+// Validate checks the structure of token segments
+func (t ClusterToken) Validate() error {
+	if !strings.HasPrefix(t.Prefix, ClusterTokenPrefix) || len(t.Prefix) == len(ClusterTokenPrefix) {
+		return fmt.Errorf("invalid cluster token prefix: %q", t.Prefix)
+	}
+	if !tokenCharset(t.Prefix[len(ClusterTokenPrefix):], "") {
+		return fmt.Errorf("invalid cluster token prefix: %q", t.Prefix)
+	}
+	if !IsKleiID(t.KleiId) {
+		return fmt.Errorf("invalid klei id in cluster token: %q", t.KleiId)
+	}
+	if len(t.Secret) == 0 || !tokenCharset(t.Secret, "+/=") {
+		return errors.New("invalid secret in cluster token")
+	}
+	return nil
+}
+
+// tokenCharset reports whether s only contains ascii letters, digits and the extra chars
+func tokenCharset(s string, extra string) bool {
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune(extra, c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Format returns the token in the form of cluster_token.txt
+func (t ClusterToken) Format() string {
+	return strings.Join([]string{t.Prefix, t.KleiId, t.Secret}, ClusterTokenSeparator)
+}
+
+// IsZero reports whether the token is empty
+func (t ClusterToken) IsZero() bool {
+	return t == ClusterToken{}
+}
+
+// ToClusterTokenTxt converts token to cluster_token.txt, without BOM and trailing line break
+func ToClusterTokenTxt(token ClusterToken) ([]byte, error) {
+	if err := token.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(token.Format()), nil
+}
+
+// Redact returns a copy of token with the secret masked
+func (t ClusterToken) Redact() ClusterToken {
+	t.Secret = redact(t.Secret)
+	return t
+}
+
+// String returns the redacted token
+func (t ClusterToken) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Redact().Format()
+}
+
+// MarshalJSON marshals the redacted token as string
+func (t ClusterToken) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// RevealedClusterToken is ClusterToken without redaction when printed or marshaled,
+// use it only when secrets are really needed.
+type RevealedClusterToken ClusterToken
+
+// Reveal opts in to print or marshal the token with secret
+func (t ClusterToken) Reveal() RevealedClusterToken {
+	return RevealedClusterToken(t)
+}
+
+// String returns the full token
+func (t RevealedClusterToken) String() string {
+	if ClusterToken(t).IsZero() {
+		return ""
+	}
+	return ClusterToken(t).Format()
+}
+
+// MarshalJSON marshals the full token as string
+func (t RevealedClusterToken) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
@@ -0,0 +1,82 @@
+package dstparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testClusterToken = "pds-g^KU_iJIpcpXi^ZKYvnX33FbqqD6ulyLYH1hNg1LY4T3zLmpBc8bURfnU="
+
+func TestParseClusterToken(t *testing.T) {
+	token, err := ParseClusterToken([]byte(testClusterToken))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "pds-g", token.Prefix)
+	assert.EqualValues(t, "KU_iJIpcpXi", token.KleiId)
+	assert.EqualValues(t, testClusterToken, token.Format())
+
+	// tolerates bom and whitespaces
+	token, err = ParseClusterToken([]byte("\xEF\xBB\xBF" + testClusterToken + "\r\n"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, testClusterToken, token.Format())
+
+	for _, malformed := range []string{
+		"",
+		"pds-g^KU_iJIpcpXi",
+		"xyz-g^KU_iJIpcpXi^ZKYvnX33",
+		"pds-g^iJIpcpXi^ZKYvnX33",
+		"pds-g^KU_iJIpcpXi^ZKYv nX33",
+		"pds-g^KU_iJIpcpXi^ZKYvnX33^abc",
+	} {
+		_, err := ParseClusterToken([]byte(malformed))
+		assert.NotNil(t, err, malformed)
+	}
+}
+
+func TestValidateClusterTokenTxt(t *testing.T) {
+	assert.Nil(t, ValidateClusterTokenTxt([]byte(testClusterToken)))
+	assert.NotNil(t, ValidateClusterTokenTxt([]byte("\xEF\xBB\xBF"+testClusterToken)))
+	assert.NotNil(t, ValidateClusterTokenTxt([]byte(testClusterToken+"\n")))
+	assert.NotNil(t, ValidateClusterTokenTxt([]byte(testClusterToken+" ")))
+}
+
+func TestClusterTokenRedact(t *testing.T) {
+	token, err := ParseClusterToken([]byte(testClusterToken))
+	assert.Nil(t, err)
+
+	str := fmt.Sprintf("%v", token)
+	assert.EqualValues(t, "pds-g^KU_iJIpcpXi^"+RedactedMask, str)
+
+	jsonData, err := json.Marshal(token)
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(jsonData), token.Secret))
+
+	revealed, err := json.Marshal(token.Reveal())
+	assert.Nil(t, err)
+	assert.EqualValues(t, `"`+testClusterToken+`"`, string(revealed))
+}
+
+func TestLoadCluster(t *testing.T) {
+	fsys := fstest.MapFS{"cluster_token.txt": {Data: []byte(testClusterToken)}}
+	for name, filename := range map[string]string{
+		"cluster.ini":                  "testdata/cluster/cluster.ini",
+		"Master/server.ini":            "testdata/cluster/server.master.ini",
+		"Master/leveldataoverride.lua": "testdata/cluster/leveldataoverride.master.lua",
+		"Caves/server.ini":             "testdata/cluster/server.cave.ini",
+		"Caves/leveldataoverride.lua":  "testdata/cluster/leveldataoverride.cave.lua",
+	} {
+		bytes, err := os.ReadFile(filename)
+		assert.Nil(t, err)
+		fsys[name] = &fstest.MapFile{Data: bytes}
+	}
+
+	cluster, err := LoadCluster(fsys)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "KU_iJIpcpXi", cluster.Token.KleiId)
+	assert.Len(t, cluster.Shards, 2)
+	t.Logf("%+v", cluster)
+}
@@ -0,0 +1,301 @@
+package dstparser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+)
+
+const (
+	LocationForest    = "forest"
+	LocationCave      = "cave"
+	LocationLavaArena = "lavaarena"
+	LocationQuagmire  = "quagmire"
+)
+
+// ShardSpec describes a shard which will be built into the cluster
+type ShardSpec struct {
+	// shard name, also used as the shard folder name, egs. Master, Caves
+	Name string
+	// world location, forest or cave
+	Location string
+	IsMaster bool
+	// ip of the machine running this shard, empty means the same machine as the master
+	Host string
+}
+
+// PortRange represents the first port of each kind of port used by shards,
+// ports are allocated incrementally per host, so shards on different machines may share the same ports.
+type PortRange struct {
+	// master_port in cluster.ini
+	MasterPort int
+	// server_port in server.ini
+	ServerPort int
+	// master_server_port in server.ini
+	MasterServerPort int
+	// authentication_port in server.ini
+	AuthenticationPort int
+}
+
+// DefaultPortRange returns the ports used by dedicated server by default
+func DefaultPortRange() PortRange {
+	return PortRange{
+		MasterPort:         10888,
+		ServerPort:         10999,
+		MasterServerPort:   27016,
+		AuthenticationPort: 8766,
+	}
+}
+
+// Shard represents a shard folder in cluster
+type Shard struct {
+	Name      string
+	Host      string
+	Server    ServerConfig
+	LevelData LevelDataOverrides
+}
+
+// Cluster represents a whole cluster folder, contains cluster.ini, cluster_token.txt and all shards
+type Cluster struct {
+	Config ClusterConfig
+	Token  ClusterToken
+	Shards []Shard
+}
+
+// BuildCluster builds a consistent cluster from the given shard specs, it fills the [SHARD] section of cluster.ini,
+// allocates non-conflicting ports for each shard and generates leveldataoverride skeletons by shard location.
+func BuildCluster(config ClusterConfig, ports PortRange, specs []ShardSpec) (Cluster, error) {
+	if len(specs) == 0 {
+		return Cluster{}, errors.New("no shard specified")
+	}
+
+	var master *ShardSpec
+	names := make(map[string]struct{}, len(specs))
+	for i, spec := range specs {
+		if len(spec.Name) == 0 {
+			return Cluster{}, fmt.Errorf("shard %d has no name", i)
+		}
+		if _, ok := names[spec.Name]; ok {
+			return Cluster{}, fmt.Errorf("duplicate shard name: %s", spec.Name)
+		}
+		names[spec.Name] = struct{}{}
+		if spec.Location != LocationForest && spec.Location != LocationCave {
+			return Cluster{}, fmt.Errorf("shard %s has unsupported location: %q", spec.Name, spec.Location)
+		}
+		if spec.IsMaster {
+			if master != nil {
+				return Cluster{}, fmt.Errorf("multiple master shards: %s, %s", master.Name, spec.Name)
+			}
+			master = &specs[i]
+		}
+	}
+	if master == nil {
+		return Cluster{}, errors.New("no master shard specified")
+	}
+
+	// empty host means the same machine as the master
+	hostOf := func(spec ShardSpec) string {
+		if len(spec.Host) == 0 {
+			return master.Host
+		}
+		return spec.Host
+	}
+
+	// cluster shard settings
+	config.Shard.ShardEnable = len(specs) > 1
+	config.Shard.MasterPort = ports.MasterPort
+	config.Shard.MasterIp = "127.0.0.1"
+	config.Shard.BindIP = "127.0.0.1"
+	for _, spec := range specs {
+		if hostOf(spec) == master.Host {
+			continue
+		}
+		// other machines need to connect to master
+		if len(master.Host) == 0 {
+			return Cluster{}, fmt.Errorf("master shard %s has no host, shard %s on %s can not connect to it", master.Name, spec.Name, spec.Host)
+		}
+		config.Shard.BindIP = "0.0.0.0"
+		config.Shard.MasterIp = master.Host
+		break
+	}
+
+	// ports in use per host
+	used := make(map[string]map[int]string)
+	allocate := func(host string, base int, shard string) (int, error) {
+		if used[host] == nil {
+			used[host] = make(map[int]string)
+		}
+		for port := base; port <= 65535; port++ {
+			if _, ok := used[host][port]; !ok {
+				used[host][port] = shard
+				return port, nil
+			}
+		}
+		return 0, fmt.Errorf("no available port from %d for shard %s", base, shard)
+	}
+	if _, err := allocate(master.Host, ports.MasterPort, master.Name); err != nil {
+		return Cluster{}, err
+	}
+
+	cluster := Cluster{Config: config}
+	secondaryId := 1
+	for _, spec := range specs {
+		var server ServerConfig
+		var err error
+		if server.Network.ServerPort, err = allocate(hostOf(spec), ports.ServerPort, spec.Name); err != nil {
+			return Cluster{}, err
+		}
+		if server.Steam.MasterServerPort, err = allocate(hostOf(spec), ports.MasterServerPort, spec.Name); err != nil {
+			return Cluster{}, err
+		}
+		if server.Steam.AuthenticationPort, err = allocate(hostOf(spec), ports.AuthenticationPort, spec.Name); err != nil {
+			return Cluster{}, err
+		}
+
+		server.Shard.Name = spec.Name
+		server.Shard.IsMaster = spec.IsMaster
+		if spec.IsMaster {
+			server.Shard.ID = "1"
+		} else {
+			secondaryId++
+			server.Shard.ID = strconv.Itoa(secondaryId)
+		}
+		server.Account.EncodeUserPath = true
+
+		cluster.Shards = append(cluster.Shards, Shard{
+			Name:      spec.Name,
+			Host:      spec.Host,
+			Server:    server,
+			LevelData: levelDataSkeleton(spec.Location),
+		})
+	}
+
+	return cluster, nil
+}
+
+// levelDataSkeleton returns the default preset of the location
+func levelDataSkeleton(location string) LevelDataOverrides {
+	preset := PresetSurvivalTogether
+	if location == LocationCave {
+		preset = PresetDSTCave
+	}
+	overrides, _ := NewLevelDataOverrides(preset)
+	return overrides
+}
+
+// Files returns the content of all files in the cluster folder, keyed by the path relative to the cluster folder,
+// egs. cluster.ini, Master/server.ini, Master/leveldataoverride.lua
+func (c Cluster) Files() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	clusterIni, err := ToClusterInI(c.Config)
+	if err != nil {
+		return nil, err
+	}
+	files["cluster.ini"] = clusterIni
+
+	if !c.Token.IsZero() {
+		token, err := ToClusterTokenTxt(c.Token)
+		if err != nil {
+			return nil, err
+		}
+		files["cluster_token.txt"] = token
+	}
+
+	for _, shard := range c.Shards {
+		serverIni, err := ToServerInI(shard.Server)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join(shard.Name, "server.ini")] = serverIni
+
+		levelData, err := ToLevelDataOverridesLua(shard.LevelData)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Join(shard.Name, "leveldataoverride.lua")] = levelData
+	}
+
+	return files, nil
+}
+
+// LoadCluster loads cluster from the cluster folder, each sub folder contains server.ini is loaded as a shard,
+// cluster_token.txt and leveldataoverride.lua are optional.
+func LoadCluster(fsys fs.FS) (Cluster, error) {
+	return loadCluster(fsys, clusterParsers{
+		clusterIni: ParseClusterInI,
+		token: func(data []byte, exists bool) (ClusterToken, error) {
+			if !exists {
+				return ClusterToken{}, nil
+			}
+			return ParseClusterToken(data)
+		},
+		serverIni: func(shard string, data []byte) (ServerConfig, error) {
+			return ParseServerInI(data)
+		},
+	})
+}
+
+// clusterParsers parses the files in cluster folder
+type clusterParsers struct {
+	clusterIni func(data []byte) (ClusterConfig, error)
+	token      func(data []byte, exists bool) (ClusterToken, error)
+	serverIni  func(shard string, data []byte) (ServerConfig, error)
+}
+
+func loadCluster(fsys fs.FS, parsers clusterParsers) (Cluster, error) {
+	var cluster Cluster
+
+	clusterIni, err := fs.ReadFile(fsys, "cluster.ini")
+	if err != nil {
+		return Cluster{}, err
+	}
+	if cluster.Config, err = parsers.clusterIni(clusterIni); err != nil {
+		return Cluster{}, fmt.Errorf("cluster.ini: %w", err)
+	}
+
+	token, err := fs.ReadFile(fsys, "cluster_token.txt")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Cluster{}, err
+	}
+	if cluster.Token, err = parsers.token(token, err == nil); err != nil {
+		return Cluster{}, fmt.Errorf("cluster_token.txt: %w", err)
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return Cluster{}, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		serverIni, err := fs.ReadFile(fsys, path.Join(entry.Name(), "server.ini"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return Cluster{}, err
+		}
+
+		shard := Shard{Name: entry.Name()}
+		if shard.Server, err = parsers.serverIni(shard.Name, serverIni); err != nil {
+			return Cluster{}, fmt.Errorf("%s/server.ini: %w", entry.Name(), err)
+		}
+
+		levelData, err := fs.ReadFile(fsys, path.Join(entry.Name(), "leveldataoverride.lua"))
+		if err == nil {
+			if shard.LevelData, err = ParseLevelDataOverrides(levelData); err != nil {
+				return Cluster{}, fmt.Errorf("%s/leveldataoverride.lua: %w", entry.Name(), err)
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return Cluster{}, err
+		}
+
+		cluster.Shards = append(cluster.Shards, shard)
+	}
+
+	return cluster, nil
+}
@@ -0,0 +1,89 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBuildCluster(t *testing.T) {
+	cluster, err := BuildCluster(ClusterConfig{}, DefaultPortRange(), []ShardSpec{
+		{Name: "Master", Location: LocationForest, IsMaster: true},
+		{Name: "Caves", Location: LocationCave},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, cluster.Shards, 2)
+	assert.True(t, cluster.Config.Shard.ShardEnable)
+	assert.EqualValues(t, "127.0.0.1", cluster.Config.Shard.MasterIp)
+
+	master, caves := cluster.Shards[0].Server, cluster.Shards[1].Server
+	assert.True(t, master.Shard.IsMaster)
+	assert.NotEqual(t, master.Network.ServerPort, caves.Network.ServerPort)
+	assert.NotEqual(t, master.Steam.MasterServerPort, caves.Steam.MasterServerPort)
+	assert.NotEqual(t, master.Steam.AuthenticationPort, caves.Steam.AuthenticationPort)
+	assert.EqualValues(t, LocationCave, cluster.Shards[1].LevelData.Location)
+
+	files, err := cluster.Files()
+	assert.Nil(t, err)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"cluster.ini",
+		"Master/server.ini", "Master/leveldataoverride.lua",
+		"Caves/server.ini", "Caves/leveldataoverride.lua",
+	}, names)
+	assert.Contains(t, string(files["cluster.ini"]), "shard_enabled")
+	assert.Contains(t, string(files["Master/server.ini"]), "is_master")
+	assert.Contains(t, string(files["Caves/leveldataoverride.lua"]), `location="cave"`)
+
+	reparsed, err := ParseServerInI(files["Caves/server.ini"])
+	assert.Nil(t, err)
+	assert.Equal(t, caves, reparsed)
+}
+
+func TestBuildClusterRemote(t *testing.T) {
+	cluster, err := BuildCluster(ClusterConfig{}, DefaultPortRange(), []ShardSpec{
+		{Name: "Master", Location: LocationForest, IsMaster: true, Host: "10.0.0.1"},
+		{Name: "Caves", Location: LocationCave, Host: "10.0.0.2"},
+		{Name: "Caves2", Location: LocationCave, Host: "10.0.0.2"},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "10.0.0.1", cluster.Config.Shard.MasterIp)
+	assert.EqualValues(t, "0.0.0.0", cluster.Config.Shard.BindIP)
+
+	// shards on different machines can share ports
+	assert.Equal(t, cluster.Shards[0].Server.Network.ServerPort, cluster.Shards[1].Server.Network.ServerPort)
+	assert.NotEqual(t, cluster.Shards[1].Server.Network.ServerPort, cluster.Shards[2].Server.Network.ServerPort)
+}
+
+func TestBuildClusterMasterHost(t *testing.T) {
+	// empty host is on the same machine as master
+	cluster, err := BuildCluster(ClusterConfig{}, DefaultPortRange(), []ShardSpec{
+		{Name: "Master", Location: LocationForest, IsMaster: true, Host: "10.0.0.1"},
+		{Name: "Caves", Location: LocationCave},
+	})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "127.0.0.1", cluster.Config.Shard.BindIP)
+	assert.NotEqual(t, cluster.Shards[0].Server.Network.ServerPort, cluster.Shards[1].Server.Network.ServerPort)
+
+	// remote shards can not reach master without host
+	_, err = BuildCluster(ClusterConfig{}, DefaultPortRange(), []ShardSpec{
+		{Name: "Master", Location: LocationForest, IsMaster: true},
+		{Name: "Caves", Location: LocationCave, Host: "10.0.0.2"},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestBuildClusterInvalid(t *testing.T) {
+	_, err := BuildCluster(ClusterConfig{}, DefaultPortRange(), []ShardSpec{
+		{Name: "Caves", Location: LocationCave},
+	})
+	assert.NotNil(t, err)
+
+	_, err = BuildCluster(ClusterConfig{}, DefaultPortRange(), []ShardSpec{
+		{Name: "Master", Location: LocationForest, IsMaster: true},
+		{Name: "Master", Location: LocationCave},
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,232 @@
+package dstparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	lua "github.com/yuin/gopher-lua"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// groups in worldgenoverride.lua, written in this order
+const (
+	WorldGenGroupMisc       = "misc"
+	WorldGenGroupUnprepared = "unprepared"
+	WorldGenGroupAnimals    = "animals"
+	WorldGenGroupMonsters   = "monsters"
+	WorldGenGroupResources  = "resources"
+)
+
+var worldGenGroupOrder = []string{
+	WorldGenGroupMisc, WorldGenGroupUnprepared, WorldGenGroupAnimals, WorldGenGroupMonsters, WorldGenGroupResources,
+}
+
+// worldGenGroups maps group of override key catalog to group in worldgenoverride.lua
+var worldGenGroups = map[string]string{
+	"misc":      WorldGenGroupMisc,
+	"global":    WorldGenGroupMisc,
+	"events":    WorldGenGroupMisc,
+	"survivors": WorldGenGroupMisc,
+	"world":     WorldGenGroupMisc,
+	"resources": WorldGenGroupResources,
+	"portal":    WorldGenGroupResources,
+	"animals":   WorldGenGroupAnimals,
+	"monsters":  WorldGenGroupMonsters,
+	"giants":    WorldGenGroupMonsters,
+}
+
+// food keys are grouped as unprepared in worldgenoverride.lua
+var worldGenUnprepared = map[string]struct{}{
+	"berrybush": {},
+	"cactus":    {},
+	"carrot":    {},
+	"mushroom":  {},
+}
+
+// WorldGenOverrideItem is an override in worldgenoverride.lua
+type WorldGenOverrideItem struct {
+	// the table which the override is in, egs. misc, animals, empty if the override is at top-level
+	Group string `mapstructure:"group"`
+	Name  string `mapstructure:"name"`
+	Value any    `mapstructure:"value"`
+}
+
+// WorldGenOverride represents the legacy worldgenoverride.lua
+type WorldGenOverride struct {
+	// overrides are ignored by the game if not enabled
+	OverrideEnabled bool   `mapstructure:"override_enabled"`
+	Preset          string `mapstructure:"preset"`
+	// overrides in the order of group and name
+	Overrides []WorldGenOverrideItem `mapstructure:"overrides"`
+}
+
+// ParseWorldGenOverride parses worldgenoverride.lua, both grouped overrides and top-level overrides are supported.
+func ParseWorldGenOverride(luaScript []byte) (WorldGenOverride, error) {
+	l := lua.NewState()
+	defer l.Close()
+	if err := l.DoString(unsafe.String(unsafe.SliceData(luaScript), len(luaScript))); err != nil {
+		return WorldGenOverride{}, err
+	}
+
+	table, ok := l.Get(-1).(*lua.LTable)
+	if !ok {
+		return WorldGenOverride{}, errors.New("worldgenoverride.lua does not return a table")
+	}
+	tableL := LTable(table)
+
+	worldGenOverride := WorldGenOverride{
+		OverrideEnabled: tableL.GetBool("override_enabled"),
+		Preset:          tableL.GetString("preset"),
+	}
+
+	table.ForEach(func(key lua.LValue, value lua.LValue) {
+		name := key.String()
+		if name == "override_enabled" || name == "preset" {
+			return
+		}
+		group, ok := value.(*lua.LTable)
+		if !ok {
+			worldGenOverride.Overrides = append(worldGenOverride.Overrides, WorldGenOverrideItem{Name: name, Value: luaToGo(value)})
+			return
+		}
+		group.ForEach(func(key lua.LValue, value lua.LValue) {
+			worldGenOverride.Overrides = append(worldGenOverride.Overrides, WorldGenOverrideItem{Group: name, Name: key.String(), Value: luaToGo(value)})
+		})
+	})
+	sortWorldGenOverrideItems(worldGenOverride.Overrides)
+
+	return worldGenOverride, nil
+}
+
+func worldGenGroupRank(group string) int {
+	for i, g := range worldGenGroupOrder {
+		if g == group {
+			return i
+		}
+	}
+	return len(worldGenGroupOrder)
+}
+
+// sortWorldGenOverrideItems sorts items by top-level first, then the group order, then name
+func sortWorldGenOverrideItems(items []WorldGenOverrideItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Group != b.Group {
+			if len(a.Group) == 0 || len(b.Group) == 0 {
+				return len(a.Group) == 0
+			}
+			if ra, rb := worldGenGroupRank(a.Group), worldGenGroupRank(b.Group); ra != rb {
+				return ra < rb
+			}
+			return a.Group < b.Group
+		}
+		return a.Name < b.Name
+	})
+}
+
+// ToLevelDataOverrides converts to LevelDataOverrides, the preset is used as the baseline and the overrides
+// are applied on it if enabled. SURVIVAL_TOGETHER is used if preset is empty.
+func (w WorldGenOverride) ToLevelDataOverrides() (LevelDataOverrides, error) {
+	presetId := w.Preset
+	if len(presetId) == 0 {
+		presetId = PresetSurvivalTogether
+	}
+	preset, err := NewLevelDataOverrides(presetId)
+	if err != nil {
+		return LevelDataOverrides{}, err
+	}
+	if !w.OverrideEnabled {
+		return preset, nil
+	}
+
+	var sparse LevelDataOverrides
+	for _, item := range w.Overrides {
+		sparse.Overrides = append(sparse.Overrides, LevelOverrideItem{Name: item.Name, Value: item.Value})
+	}
+	return sparse.Expand(preset), nil
+}
+
+// ToWorldGenOverride converts LevelDataOverrides to the legacy worldgenoverride.lua format for servers still using it,
+// only overrides differ from the preset are kept and grouped as the game does. The preset is WorldGenId or Id
+// if it is a built-in preset, otherwise the default preset of Location.
+func ToWorldGenOverride(overrides LevelDataOverrides) WorldGenOverride {
+	presetId := PresetSurvivalTogether
+	if overrides.Location == LocationCave {
+		presetId = PresetDSTCave
+	}
+	for _, id := range []string{overrides.WorldGenId, overrides.Id} {
+		if _, err := NewLevelDataOverrides(id); err == nil {
+			presetId = id
+			break
+		}
+	}
+	preset, _ := NewLevelDataOverrides(presetId)
+
+	worldGenOverride := WorldGenOverride{OverrideEnabled: true, Preset: presetId}
+	for _, item := range overrides.Delta(preset).Overrides {
+		worldGenOverride.Overrides = append(worldGenOverride.Overrides, WorldGenOverrideItem{
+			Group: worldGenGroupOf(item.Name),
+			Name:  item.Name,
+			Value: item.Value,
+		})
+	}
+	sortWorldGenOverrideItems(worldGenOverride.Overrides)
+
+	return worldGenOverride
+}
+
+// worldGenGroupOf returns the group of override key in worldgenoverride.lua, misc for unknown keys
+func worldGenGroupOf(name string) string {
+	if _, ok := worldGenUnprepared[name]; ok {
+		return WorldGenGroupUnprepared
+	}
+	if key, ok := LookupOverrideKey(name); ok {
+		if group, ok := worldGenGroups[key.Group]; ok {
+			return group
+		}
+	}
+	return WorldGenGroupMisc
+}
+
+// ToWorldGenOverrideLua converts WorldGenOverride to worldgenoverride.lua
+func ToWorldGenOverrideLua(worldGenOverride WorldGenOverride) ([]byte, error) {
+	items := append([]WorldGenOverrideItem(nil), worldGenOverride.Overrides...)
+	sortWorldGenOverrideItems(items)
+
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString("return {\n")
+	fmt.Fprintf(buffer, "  override_enabled=%t,\n", worldGenOverride.OverrideEnabled)
+	fmt.Fprintf(buffer, "  preset=%s,\n", luaString(worldGenOverride.Preset))
+
+	var entries []string
+	for i := 0; i < len(items); {
+		group, indent := items[i].Group, "    "
+		if len(group) == 0 {
+			indent = "  "
+		}
+		var groupEntries []string
+		for ; i < len(items) && items[i].Group == group; i++ {
+			value, err := luaLiteral(items[i].Value, indent)
+			if err != nil {
+				return nil, fmt.Errorf("override %s: %w", items[i].Name, err)
+			}
+			groupEntries = append(groupEntries, luaKey(items[i].Name)+"="+value)
+		}
+		if len(group) == 0 {
+			for _, entry := range groupEntries {
+				entries = append(entries, "  "+entry)
+			}
+			continue
+		}
+		entries = append(entries, "  "+luaKey(group)+"="+luaTable(groupEntries, true, "  "))
+	}
+
+	if len(entries) > 0 {
+		buffer.WriteString(strings.Join(entries, ",\n"))
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString("}\n")
+	return buffer.Bytes(), nil
+}
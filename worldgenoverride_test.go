@@ -0,0 +1,79 @@
+package dstparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestParseWorldGenOverride(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/worldgenoverride.lua")
+	assert.Nil(t, err)
+	worldGenOverride, err := ParseWorldGenOverride(bytes)
+	assert.Nil(t, err)
+	t.Log(worldGenOverride)
+
+	assert.True(t, worldGenOverride.OverrideEnabled)
+	assert.Equal(t, PresetSurvivalTogether, worldGenOverride.Preset)
+	assert.Equal(t, WorldGenOverrideItem{Name: "spawnmode", Value: "scatter"}, worldGenOverride.Overrides[0])
+	assert.Contains(t, worldGenOverride.Overrides, WorldGenOverrideItem{Group: WorldGenGroupMisc, Name: "day", Value: "longdusk"})
+	assert.Contains(t, worldGenOverride.Overrides, WorldGenOverrideItem{Group: WorldGenGroupUnprepared, Name: "berrybush", Value: "rare"})
+
+	_, err = ParseWorldGenOverride([]byte(`return "not a table"`))
+	assert.NotNil(t, err)
+}
+
+func TestWorldGenOverrideToLevelDataOverrides(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/worldgenoverride.lua")
+	assert.Nil(t, err)
+	worldGenOverride, err := ParseWorldGenOverride(bytes)
+	assert.Nil(t, err)
+
+	overrides, err := worldGenOverride.ToLevelDataOverrides()
+	assert.Nil(t, err)
+	assert.Nil(t, overrides.Validate())
+	assert.Equal(t, PresetSurvivalTogether, overrides.Id)
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "day", Value: "longdusk"})
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "spawnmode", Value: "scatter"})
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "alternatehunt", Value: "default"})
+
+	worldGenOverride.OverrideEnabled = false
+	overrides, err = worldGenOverride.ToLevelDataOverrides()
+	assert.Nil(t, err)
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "day", Value: "default"})
+
+	worldGenOverride.Preset = "NOT_A_PRESET"
+	_, err = worldGenOverride.ToLevelDataOverrides()
+	assert.NotNil(t, err)
+}
+
+func TestToWorldGenOverride(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/worldgenoverride.lua")
+	assert.Nil(t, err)
+	worldGenOverride, err := ParseWorldGenOverride(bytes)
+	assert.Nil(t, err)
+	overrides, err := worldGenOverride.ToLevelDataOverrides()
+	assert.Nil(t, err)
+
+	converted := ToWorldGenOverride(overrides)
+	assert.True(t, converted.OverrideEnabled)
+	assert.Equal(t, PresetSurvivalTogether, converted.Preset)
+	assert.Contains(t, converted.Overrides, WorldGenOverrideItem{Group: WorldGenGroupMisc, Name: "spawnmode", Value: "scatter"})
+	assert.Contains(t, converted.Overrides, WorldGenOverrideItem{Group: WorldGenGroupMonsters, Name: "deerclops", Value: "rare"})
+	assert.NotContains(t, converted.Overrides, WorldGenOverrideItem{Group: WorldGenGroupResources, Name: "flint", Value: "default"})
+
+	worldGenOverrideLua, err := ToWorldGenOverrideLua(converted)
+	assert.Nil(t, err)
+	t.Log(string(worldGenOverrideLua))
+	reparsed, err := ParseWorldGenOverride(worldGenOverrideLua)
+	assert.Nil(t, err)
+	assert.Equal(t, converted, reparsed)
+
+	// the master testdata is ENDLESS with WILDERNESS world generation
+	bytes, err = os.ReadFile("testdata/cluster/leveldataoverride.master.lua")
+	assert.Nil(t, err)
+	overrides, err = ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+	converted = ToWorldGenOverride(overrides)
+	assert.Equal(t, PresetWilderness, converted.Preset)
+}
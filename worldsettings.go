@@ -0,0 +1,233 @@
+package dstparser
+
+import (
+	"errors"
+	"reflect"
+)
+
+// SeasonLength is the value of autumn, winter, spring and summer
+type SeasonLength string
+
+const (
+	SeasonNone      SeasonLength = "noseason"
+	SeasonVeryShort SeasonLength = "veryshortseason"
+	SeasonShort     SeasonLength = "shortseason"
+	SeasonDefault   SeasonLength = "default"
+	SeasonLong      SeasonLength = "longseason"
+	SeasonVeryLong  SeasonLength = "verylongseason"
+	SeasonRandom    SeasonLength = "random"
+)
+
+// SeasonStart is the value of season_start
+type SeasonStart string
+
+const (
+	SeasonStartAutumn         SeasonStart = "default"
+	SeasonStartWinter         SeasonStart = "winter"
+	SeasonStartSpring         SeasonStart = "spring"
+	SeasonStartSummer         SeasonStart = "summer"
+	SeasonStartAutumnOrSpring SeasonStart = "autumnorspring"
+	SeasonStartWinterOrSummer SeasonStart = "winterorsummer"
+	SeasonStartRandom         SeasonStart = "random"
+)
+
+// DayType is the value of day
+type DayType string
+
+const (
+	DayDefault   DayType = "default"
+	DayLongDay   DayType = "longday"
+	DayLongDusk  DayType = "longdusk"
+	DayLongNight DayType = "longnight"
+	DayNoDay     DayType = "noday"
+	DayNoDusk    DayType = "nodusk"
+	DayNoNight   DayType = "nonight"
+	DayOnlyDay   DayType = "onlyday"
+	DayOnlyDusk  DayType = "onlydusk"
+	DayOnlyNight DayType = "onlynight"
+)
+
+// SpecialEvent is the value of specialevent
+type SpecialEvent string
+
+const (
+	SpecialEventNone               SpecialEvent = "none"
+	SpecialEventAuto               SpecialEvent = "default"
+	SpecialEventHallowedNights     SpecialEvent = "hallowed_nights"
+	SpecialEventWintersFeast       SpecialEvent = "winters_feast"
+	SpecialEventYearOfTheGobbler   SpecialEvent = "year_of_the_gobbler"
+	SpecialEventYearOfTheVarg      SpecialEvent = "year_of_the_varg"
+	SpecialEventYearOfThePig       SpecialEvent = "year_of_the_pig"
+	SpecialEventYearOfTheCarrat    SpecialEvent = "year_of_the_carrat"
+	SpecialEventYearOfTheBeefalo   SpecialEvent = "year_of_the_beefalo"
+	SpecialEventYearOfTheCatcoon   SpecialEvent = "year_of_the_catcoon"
+	SpecialEventYearOfTheBunnyman  SpecialEvent = "year_of_the_bunnyman"
+	SpecialEventYearOfTheDragonfly SpecialEvent = "year_of_the_dragonfly"
+	SpecialEventCrowCarnival       SpecialEvent = "crow_carnival"
+)
+
+// Frequency is the value of settings like hounds, deerclops, weather
+type Frequency string
+
+const (
+	FrequencyNever   Frequency = "never"
+	FrequencyRare    Frequency = "rare"
+	FrequencyDefault Frequency = "default"
+	FrequencyOften   Frequency = "often"
+	FrequencyAlways  Frequency = "always"
+)
+
+// Rate is the value of regrowth
+type Rate string
+
+const (
+	RateVerySlow Rate = "veryslow"
+	RateSlow     Rate = "slow"
+	RateDefault  Rate = "default"
+	RateFast     Rate = "fast"
+	RateVeryFast Rate = "veryfast"
+)
+
+// Seasons is the season settings, caves follow the seasons of master
+type Seasons struct {
+	Autumn SeasonLength `override:"autumn"`
+	Winter SeasonLength `override:"winter"`
+	Spring SeasonLength `override:"spring"`
+	Summer SeasonLength `override:"summer"`
+	Start  SeasonStart  `override:"season_start"`
+}
+
+// Weather is the weather settings
+type Weather struct {
+	Rain          Frequency `override:"weather"`
+	Lightning     Frequency `override:"lightning"`
+	FrogRain      Frequency `override:"frograin"`
+	Wildfires     Frequency `override:"wildfires"`
+	MeteorShowers Frequency `override:"meteorshowers"`
+	Earthquakes   Frequency `override:"earthquakes"`
+}
+
+// Hostiles is the settings of hostile creatures
+type Hostiles struct {
+	Hounds              Frequency `override:"hounds"`
+	Krampus             Frequency `override:"krampus"`
+	Spiders             Frequency `override:"spiders_setting"`
+	Merms               Frequency `override:"merms"`
+	Treeguards          Frequency `override:"liefs"`
+	ShadowCreatures     Frequency `override:"shadowcreatures"`
+	BrightmareCreatures Frequency `override:"brightmarecreatures"`
+}
+
+// Giants is the settings of giants
+type Giants struct {
+	Deerclops  Frequency `override:"deerclops"`
+	Bearger    Frequency `override:"bearger"`
+	MooseGoose Frequency `override:"goosemoose"`
+	Dragonfly  Frequency `override:"dragonfly"`
+	Antlion    Frequency `override:"antliontribute"`
+	BeeQueen   Frequency `override:"beequeen"`
+	Klaus      Frequency `override:"klaus"`
+	Malbatross Frequency `override:"malbatross"`
+	CrabKing   Frequency `override:"crabking"`
+	Toadstool  Frequency `override:"toadstool"`
+}
+
+// WorldSettings is a typed view of frequently used overrides in leveldataoverride.lua,
+// each field is tagged with its override key, empty means the key is not available in the location.
+type WorldSettings struct {
+	Seasons      Seasons
+	Day          DayType      `override:"day"`
+	SpecialEvent SpecialEvent `override:"specialevent"`
+	Regrowth     Rate         `override:"regrowth"`
+	Weather      Weather
+	Hostiles     Hostiles
+	Giants       Giants
+}
+
+// walkWorldSettings calls fn with override key for every tagged field of settings, nested structs included
+func walkWorldSettings(settings reflect.Value, fn func(name string, field reflect.Value)) {
+	for i := 0; i < settings.NumField(); i++ {
+		field := settings.Field(i)
+		if field.Kind() == reflect.Struct {
+			walkWorldSettings(field, fn)
+			continue
+		}
+		if name := settings.Type().Field(i).Tag.Get("override"); len(name) > 0 {
+			fn(name, field)
+		}
+	}
+}
+
+// WorldSettings returns the typed view of overrides, keys missing in overrides are taken as
+// the default value of Location.
+func (l LevelDataOverrides) WorldSettings() WorldSettings {
+	var settings WorldSettings
+	walkWorldSettings(reflect.ValueOf(&settings).Elem(), func(name string, field reflect.Value) {
+		if value, ok := l.baseline(name); ok {
+			if s, ok := value.(string); ok {
+				field.SetString(s)
+			}
+		}
+	})
+	return settings
+}
+
+// SetWorldSettings writes non-empty fields of settings into overrides, fields equal to the value WorldSettings
+// returns are skipped so that sparse overrides stay sparse, other overrides are left untouched.
+// Nothing is written if any value is illegal or not available in Location, the errors are reported as
+// *OverrideError joined in the returned error, same as Validate.
+func (l *LevelDataOverrides) SetWorldSettings(settings WorldSettings) error {
+	knownLocation := isKnownLocation(l.Location)
+
+	var errs []error
+	var items []LevelOverrideItem
+	walkWorldSettings(reflect.ValueOf(settings), func(name string, field reflect.Value) {
+		value := field.String()
+		if len(value) == 0 {
+			return
+		}
+		if current, ok := l.baseline(name); ok {
+			if s, ok := current.(string); ok && s == value {
+				return
+			}
+		}
+		key, ok := LookupOverrideKey(name)
+		switch {
+		case !ok:
+			errs = append(errs, &OverrideError{Name: name, Value: value, Err: ErrUnknownOverride})
+		case !key.Allows(value):
+			errs = append(errs, &OverrideError{Name: name, Value: value, Err: ErrIllegalOverrideValue})
+		case knownLocation && !key.AvailableIn(l.Location):
+			errs = append(errs, &OverrideError{Name: name, Value: value, Err: ErrOverrideLocation})
+		default:
+			items = append(items, LevelOverrideItem{Name: name, Value: value})
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		l.SetOverride(item.Name, item.Value)
+	}
+	return nil
+}
+
+// SetOverride sets the value of override name, a new key is inserted before the first key greater than it
+// so that overrides sorted by name stay sorted.
+func (l *LevelDataOverrides) SetOverride(name string, value any) {
+	for i, item := range l.Overrides {
+		if item.Name == name {
+			l.Overrides[i].Value = value
+			return
+		}
+	}
+
+	i := 0
+	for i < len(l.Overrides) && l.Overrides[i].Name < name {
+		i++
+	}
+	l.Overrides = append(l.Overrides, LevelOverrideItem{})
+	copy(l.Overrides[i+1:], l.Overrides[i:])
+	l.Overrides[i] = LevelOverrideItem{Name: name, Value: value}
+}
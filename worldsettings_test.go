@@ -0,0 +1,136 @@
+package dstparser
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestWorldSettingsKeys(t *testing.T) {
+	walkWorldSettings(reflect.ValueOf(WorldSettings{}), func(name string, field reflect.Value) {
+		_, ok := LookupOverrideKey(name)
+		assert.True(t, ok, name)
+	})
+
+	key, _ := LookupOverrideKey("specialevent")
+	for _, event := range []SpecialEvent{SpecialEventNone, SpecialEventAuto, SpecialEventYearOfTheDragonfly, SpecialEventCrowCarnival} {
+		assert.True(t, key.Allows(string(event)), event)
+	}
+}
+
+func TestLevelDataOverridesWorldSettings(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/leveldataoverride.master.lua")
+	assert.Nil(t, err)
+	overrides, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+
+	settings := overrides.WorldSettings()
+	assert.Equal(t, SeasonDefault, settings.Seasons.Autumn)
+	assert.Equal(t, SeasonStartAutumn, settings.Seasons.Start)
+	assert.Equal(t, DayDefault, settings.Day)
+	assert.Equal(t, FrequencyDefault, settings.Giants.Deerclops)
+	// not available in forest
+	assert.Empty(t, settings.Weather.Earthquakes)
+	assert.Empty(t, settings.Giants.Toadstool)
+
+	caves, err := NewLevelDataOverrides(PresetDSTCave)
+	assert.Nil(t, err)
+	assert.Empty(t, caves.WorldSettings().Seasons.Winter)
+	assert.Equal(t, FrequencyDefault, caves.WorldSettings().Weather.Earthquakes)
+
+	// keys missing in overrides are taken as default values
+	sparse := LevelDataOverrides{Location: LocationForest, Overrides: []LevelOverrideItem{{Name: "day", Value: "onlynight"}}}
+	assert.Equal(t, DayOnlyNight, sparse.WorldSettings().Day)
+	assert.Equal(t, RateDefault, sparse.WorldSettings().Regrowth)
+}
+
+func TestLevelDataOverridesSetWorldSettings(t *testing.T) {
+	bytes, err := os.ReadFile("testdata/cluster/leveldataoverride.master.lua")
+	assert.Nil(t, err)
+	overrides, err := ParseLevelDataOverrides(bytes)
+	assert.Nil(t, err)
+	overrides.SetOverride("my_mod_key", "my_value")
+	original := overrides
+	original.Overrides = append([]LevelOverrideItem(nil), overrides.Overrides...)
+
+	settings := overrides.WorldSettings()
+	settings.Seasons.Winter = SeasonVeryLong
+	settings.Day = DayLongDusk
+	settings.Hostiles.Hounds = FrequencyNever
+	assert.Nil(t, overrides.SetWorldSettings(settings))
+	assert.Equal(t, settings, overrides.WorldSettings())
+
+	// only changed keys are written, unknown keys are untouched
+	var paths []string
+	for _, change := range original.Diff(overrides) {
+		paths = append(paths, change.Path())
+	}
+	assert.Equal(t, []string{"overrides.day", "overrides.hounds", "overrides.winter"}, paths)
+	assert.Contains(t, overrides.Overrides, LevelOverrideItem{Name: "my_mod_key", Value: "my_value"})
+
+	// empty fields are skipped, missing keys are inserted in order
+	sparse := LevelDataOverrides{Location: LocationForest, Overrides: []LevelOverrideItem{{Name: "autumn", Value: "default"}, {Name: "winter", Value: "default"}}}
+	assert.Nil(t, sparse.SetWorldSettings(WorldSettings{Day: DayOnlyDay}))
+	assert.Equal(t, []LevelOverrideItem{{Name: "autumn", Value: "default"}, {Name: "day", Value: "onlyday"}, {Name: "winter", Value: "default"}}, sparse.Overrides)
+
+	// fields equal to the current value are skipped, sparse overrides stay sparse
+	sparse = LevelDataOverrides{Location: LocationForest, Overrides: []LevelOverrideItem{{Name: "day", Value: "longdusk"}}}
+	assert.Nil(t, sparse.SetWorldSettings(sparse.WorldSettings()))
+	assert.Equal(t, []LevelOverrideItem{{Name: "day", Value: "longdusk"}}, sparse.Overrides)
+
+	settings = sparse.WorldSettings()
+	settings.Giants.Deerclops = FrequencyNever
+	assert.Nil(t, sparse.SetWorldSettings(settings))
+	assert.Equal(t, []LevelOverrideItem{{Name: "day", Value: "longdusk"}, {Name: "deerclops", Value: "never"}}, sparse.Overrides)
+
+	// values which are not string are replaced
+	sparse = LevelDataOverrides{Location: LocationForest, Overrides: []LevelOverrideItem{{Name: "day", Value: 1.0}}}
+	assert.Empty(t, sparse.WorldSettings().Day)
+	assert.Nil(t, sparse.SetWorldSettings(WorldSettings{Day: DayDefault}))
+	assert.Equal(t, []LevelOverrideItem{{Name: "day", Value: "default"}}, sparse.Overrides)
+}
+
+func TestLevelDataOverridesSetWorldSettingsInvalid(t *testing.T) {
+	caves, err := NewLevelDataOverrides(PresetDSTCave)
+	assert.Nil(t, err)
+	original := caves.WorldSettings()
+
+	settings := original
+	settings.Day = DayLongDusk
+	settings.Seasons.Autumn = SeasonLong
+	settings.Giants.Deerclops = "lots"
+	err = caves.SetWorldSettings(settings)
+	assert.NotNil(t, err)
+	t.Log(err)
+	assert.True(t, errors.Is(err, ErrOverrideLocation))
+	assert.True(t, errors.Is(err, ErrIllegalOverrideValue))
+	// nothing is written
+	assert.Equal(t, original, caves.WorldSettings())
+}
+
+func TestLevelDataOverridesSetOverride(t *testing.T) {
+	var overrides LevelDataOverrides
+	overrides.SetOverride("day", "longdusk")
+	overrides.SetOverride("autumn", "longseason")
+	overrides.SetOverride("winter", "shortseason")
+	overrides.SetOverride("hounds", "never")
+	assert.Equal(t, []LevelOverrideItem{
+		{Name: "autumn", Value: "longseason"},
+		{Name: "day", Value: "longdusk"},
+		{Name: "hounds", Value: "never"},
+		{Name: "winter", Value: "shortseason"},
+	}, overrides.Overrides)
+
+	// existing key is replaced in place
+	overrides.SetOverride("day", "default")
+	overrides.SetOverride("my_mod_key", 1.5)
+	assert.Equal(t, []LevelOverrideItem{
+		{Name: "autumn", Value: "longseason"},
+		{Name: "day", Value: "default"},
+		{Name: "hounds", Value: "never"},
+		{Name: "my_mod_key", Value: 1.5},
+		{Name: "winter", Value: "shortseason"},
+	}, overrides.Overrides)
+}